@@ -0,0 +1,98 @@
+package login
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fosrl/cli/internal/api"
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// loginWithAPIKey verifies an API key against the server and stores
+// it as an API key account
+func loginWithAPIKey(cmd *cobra.Command, accountStore *config.AccountStore, hostname string, opts *LoginCmdOpts) error {
+	apiKey := opts.APIKey
+	if apiKey == "-" {
+		key, err := readAPIKeyFromStdin()
+		if err != nil {
+			logger.Error("Error: %v", err)
+			return err
+		}
+		apiKey = key
+	}
+
+	if opts.OrgID == "" {
+		err := errors.New("--org is required when logging in with an API key")
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	client, err := api.InitClientWithAPIKey(hostname, apiKey)
+	if err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	// Verify the key by fetching the organization it should grant access to
+	if _, err := client.GetOrg(cmd.Context(), opts.OrgID); err != nil {
+		logger.Error("Failed to verify API key: %v", err)
+		if errors.Is(err, api.ErrUnauthorized) || errors.Is(err, api.ErrForbidden) {
+			logger.Info("Make sure the API key is valid and has access to organization %s", opts.OrgID)
+		} else {
+			utils.LogErrorHint(err)
+		}
+		return err
+	}
+
+	account := config.NewAPIKeyAccount(hostname, apiKey, opts.OrgID)
+
+	// Keep any client credentials that were stored for this key before
+	if existing, exists := accountStore.Accounts[account.UserID]; exists {
+		account.OlmCredentials = existing.OlmCredentials
+	}
+
+	accountStore.Accounts[account.UserID] = account
+	accountStore.ActiveUserID = account.UserID
+
+	if err := accountStore.Save(); err != nil {
+		logger.Error("Failed to save account store: %s", err)
+		return err
+	}
+
+	logger.Success("Logged in with %s @ %s", account.DisplayName(), hostname)
+
+	return nil
+}
+
+// readAPIKeyFromStdin reads a single API key line from standard input
+func readAPIKeyFromStdin() (string, error) {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read API key from stdin: %w", err)
+	}
+
+	apiKey := strings.TrimSpace(line)
+	if apiKey == "" {
+		return "", errors.New("no API key provided on stdin")
+	}
+
+	return apiKey, nil
+}
+
+// normalizeHostname preserves the protocol, defaulting to https if
+// none was specified, and removes any trailing slash
+func normalizeHostname(hostname string) string {
+	hostname = strings.TrimSuffix(hostname, "/")
+
+	if !strings.HasPrefix(hostname, "http://") && !strings.HasPrefix(hostname, "https://") {
+		hostname = "https://" + hostname
+	}
+
+	return hostname
+}
@@ -2,8 +2,10 @@ package login
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -14,6 +16,7 @@ import (
 	"github.com/fosrl/cli/internal/logger"
 	"github.com/fosrl/cli/internal/utils"
 	"github.com/pkg/browser"
+	"github.com/skip2/go-qrcode"
 	"github.com/spf13/cobra"
 )
 
@@ -33,7 +36,21 @@ func getDeviceName() string {
 	return hostname
 }
 
-func loginWithWeb(hostname string) (string, error) {
+// webLoginOptions controls how the device code login is presented
+type webLoginOptions struct {
+	// NoBrowser prints the login URL and a QR code instead of
+	// offering to open a browser, for use over SSH
+	NoBrowser bool
+	// Timeout limits how long to wait for the login to be
+	// completed. Zero waits until the device code expires.
+	Timeout time.Duration
+}
+
+// defaultCodeExpiry is used when the server does not say when
+// the device code expires
+const defaultCodeExpiry = 5 * time.Minute
+
+func loginWithWeb(ctx context.Context, hostname string, opts webLoginOptions) (string, error) {
 	// Build base URL for login (use hostname as-is, StartDeviceWebAuth will add /api/v1)
 	baseURL := hostname
 
@@ -48,6 +65,12 @@ func loginWithWeb(hostname string) (string, error) {
 		return "", fmt.Errorf("failed to create API client: %w", err)
 	}
 
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
 	// Get device name
 	deviceName := getDeviceName()
 
@@ -57,42 +80,50 @@ func loginWithWeb(hostname string) (string, error) {
 		DeviceName:      deviceName,
 	}
 
-	startResp, err := api.StartDeviceWebAuth(loginClient, startReq)
+	startResp, err := api.StartDeviceWebAuth(ctx, loginClient, startReq)
 	if err != nil {
 		return "", fmt.Errorf("failed to start device web auth: %w", err)
 	}
 
 	code := startResp.Code
 	// Calculate expiry time from relative seconds
-	expiresAt := time.Now().Add(time.Duration(startResp.ExpiresInSeconds) * time.Second)
+	codeExpiry := time.Duration(startResp.ExpiresInSeconds) * time.Second
+	if codeExpiry <= 0 {
+		codeExpiry = defaultCodeExpiry
+	}
+	expiresAt := time.Now().Add(codeExpiry)
 
 	// Build the base login URL (without query parameter) for display
 	baseLoginURL := fmt.Sprintf("%s/auth/login/device", strings.TrimSuffix(hostname, "/"))
 	// Build the login URL with code as query parameter for browser
-	loginURL := fmt.Sprintf("%s?code=%s", baseLoginURL, code)
-
-	// Display code and instructions (similar to GH CLI format)
-	logger.Info("First copy your one-time code: %s", code)
-	logger.Info("Press Enter to open %s in your browser...", baseLoginURL)
-
-	// Wait for Enter in a goroutine (non-blocking) and open browser when pressed
-	go func() {
-		reader := bufio.NewReader(os.Stdin)
-		_, err := reader.ReadString('\n')
-		if err == nil {
-			// User pressed Enter, open browser
-			if err := browser.OpenURL(loginURL); err != nil {
-				// Don't fail if browser can't be opened, just warn
-				logger.Warning("Failed to open browser automatically")
-				logger.Info("Please manually visit: %s", baseLoginURL)
+	loginURL := fmt.Sprintf("%s?code=%s", baseLoginURL, url.QueryEscape(code))
+
+	if opts.NoBrowser {
+		// Never read stdin here, so polling also works in sessions
+		// where the terminal input is not available to us.
+		printDeviceLoginInstructions(loginURL, code, codeExpiry)
+	} else {
+		// Display code and instructions (similar to GH CLI format)
+		logger.Info("First copy your one-time code: %s", code)
+		logger.Info("Press Enter to open %s in your browser...", baseLoginURL)
+
+		// Wait for Enter in a goroutine (non-blocking) and open browser when pressed
+		go func() {
+			reader := bufio.NewReader(os.Stdin)
+			_, err := reader.ReadString('\n')
+			if err == nil {
+				// User pressed Enter, open browser
+				if err := browser.OpenURL(loginURL); err != nil {
+					// Don't fail if browser can't be opened, just warn
+					logger.Warning("Failed to open browser automatically")
+					logger.Info("Please manually visit: %s", baseLoginURL)
+				}
 			}
-		}
-	}()
+		}()
+	}
 
 	// Poll for verification (starts immediately, doesn't wait for Enter)
 	pollInterval := 1 * time.Second
-	startTime := time.Now()
-	maxPollDuration := 5 * time.Minute
 
 	var token string
 
@@ -105,20 +136,19 @@ func loginWithWeb(hostname string) (string, error) {
 			return "", fmt.Errorf("code expired. Please try again")
 		}
 
-		// Check if we've exceeded max polling duration
-		if time.Since(startTime) > maxPollDuration {
-			logger.Error("Polling timed out after %v", maxPollDuration)
-			return "", fmt.Errorf("polling timeout. Please try again")
-		}
-
 		// Poll for verification status
-		pollResp, message, err := api.PollDeviceWebAuth(loginClient, code)
-		// print debug info
-		logger.Debug("Polling response: %+v, message: %s, err: %v", pollResp, message, err)
+		pollResp, message, err := api.PollDeviceWebAuth(ctx, loginClient, code)
 		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				logger.Error("Timed out after %v waiting for login", opts.Timeout)
+				return "", fmt.Errorf("login timeout. Please try again")
+			}
 			logger.Error("Error polling device web auth: %v", err)
+			utils.LogErrorHint(err)
 			return "", fmt.Errorf("failed to poll device web auth: %w", err)
 		}
+		// print debug info (never the response itself, it carries the session token)
+		logger.Debug("Polling response: verified=%t, message: %s", pollResp.Verified, message)
 
 		// Check verification status
 		if pollResp.Verified {
@@ -136,13 +166,50 @@ func loginWithWeb(hostname string) (string, error) {
 			return "", fmt.Errorf("code expired or not found. Please try again")
 		}
 
-		// Wait before next poll
-		time.Sleep(pollInterval)
+		// Wait before next poll, stopping early if cancelled
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				logger.Error("Timed out after %v waiting for login", opts.Timeout)
+				return "", fmt.Errorf("login timeout. Please try again")
+			}
+			return "", ctx.Err()
+		case <-time.After(pollInterval):
+		}
 	}
 }
 
+// printDeviceLoginInstructions prints the login URL, the one-time code
+// and a QR code of the URL for scanning with another device
+func printDeviceLoginInstructions(loginURL, code string, expiresIn time.Duration) {
+	logger.Info("To log in, visit the following URL on any device:")
+	fmt.Println()
+	logger.Info("  %s", loginURL)
+	fmt.Println()
+
+	if qr, err := qrcode.New(loginURL, qrcode.Low); err == nil {
+		fmt.Print(qr.ToSmallString(false))
+	} else {
+		logger.Debug("Failed to render QR code: %v", err)
+	}
+
+	logger.Info("Your one-time code is: %s", code)
+	logger.Info("Waiting for login (code expires in %v)...", expiresIn.Round(time.Second))
+}
+
+// isSSHSession reports whether the CLI is running in an SSH session,
+// where a browser on this host is not useful to the user
+func isSSHSession() bool {
+	return os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != ""
+}
+
 type LoginCmdOpts struct {
-	Hostname string
+	Hostname  string
+	Method    string
+	NoBrowser bool
+	Timeout   time.Duration
+	APIKey    string
+	OrgID     string
 }
 
 func LoginCmd() *cobra.Command {
@@ -151,7 +218,19 @@ func LoginCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "login [hostname]",
 		Short: "Login to Pangolin",
-		Long:  "Interactive login to select your hosting option and configure access.",
+		Long: `Interactive login to select your hosting option and configure access.
+
+By default, login happens in a browser through a one-time device code.
+Use --no-browser to print the login URL and a QR code to complete the
+login from another device instead; this is the default over SSH.
+Use --method password to log in with email, password and an optional
+two-factor code directly in the terminal, on hosts without a browser.
+
+Use --api-key to authenticate with an org or root API key instead, for
+headless use on servers and CI runners. Pass "-" to read the key from
+standard input. Without logging in, an API key can also be provided
+through PANGOLIN_API_KEY, with its host and organization in
+PANGOLIN_HOST and PANGOLIN_ORG.`,
 		Args: func(cmd *cobra.Command, args []string) error {
 			if err := cobra.MaximumNArgs(1)(cmd, args); err != nil {
 				return err
@@ -163,6 +242,31 @@ func LoginCmd() *cobra.Command {
 
 			return nil
 		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if opts.OrgID != "" && opts.APIKey == "" {
+				return errors.New("--org can only be used with --api-key")
+			}
+
+			switch LoginMethod(opts.Method) {
+			case LoginMethodWeb, LoginMethodPassword:
+			default:
+				return fmt.Errorf("invalid login method %q: must be one of %q or %q", opts.Method, LoginMethodWeb, LoginMethodPassword)
+			}
+
+			if opts.APIKey != "" && cmd.Flags().Changed("method") {
+				return errors.New("--method cannot be used with --api-key")
+			}
+
+			if LoginMethod(opts.Method) != LoginMethodWeb && (opts.NoBrowser || opts.Timeout != 0) {
+				return errors.New("--no-browser and --timeout can only be used with the web login method")
+			}
+
+			if opts.Timeout < 0 {
+				return errors.New("--timeout must not be negative")
+			}
+
+			return nil
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			if err := loginMain(cmd, &opts); err != nil {
 				os.Exit(1)
@@ -170,6 +274,17 @@ func LoginCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().StringVar(&opts.Method, "method", string(LoginMethodWeb), "Login `method` to use: web or password")
+	cmd.Flags().BoolVar(&opts.NoBrowser, "no-browser", false, "Print the login URL and a QR code instead of opening a browser")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 0, "Maximum `duration` to wait for the login to complete (default: until the code expires)")
+	cmd.Flags().StringVar(&opts.APIKey, "api-key", "", "Authenticate with an API `key` instead of a browser login")
+	cmd.Flags().StringVar(&opts.OrgID, "org", "", "Organization `ID` to use with the API key")
+
+	_ = cmd.RegisterFlagCompletionFunc("method", cobra.FixedCompletions(
+		[]string{string(LoginMethodWeb), string(LoginMethodPassword)},
+		cobra.ShellCompDirectiveNoFileComp,
+	))
+
 	return cmd
 }
 
@@ -179,6 +294,15 @@ func loginMain(cmd *cobra.Command, opts *LoginCmdOpts) error {
 
 	hostname := opts.Hostname
 
+	// API key logins are meant to be non-interactive, so default
+	// to the cloud instance rather than prompting for a host.
+	if opts.APIKey != "" {
+		if hostname == "" {
+			hostname = "app.pangolin.net"
+		}
+		return loginWithAPIKey(cmd, accountStore, normalizeHostname(hostname), opts)
+	}
+
 	// If hostname was provided, skip hosting option selection
 	if hostname == "" {
 		var hostingOption HostingOption
@@ -222,16 +346,19 @@ func loginMain(cmd *cobra.Command, opts *LoginCmdOpts) error {
 		}
 	}
 
-	// Normalize hostname (preserve protocol, remove trailing slash)
-	hostname = strings.TrimSuffix(hostname, "/")
-
-	// If no protocol specified, default to https
-	if !strings.HasPrefix(hostname, "http://") && !strings.HasPrefix(hostname, "https://") {
-		hostname = "https://" + hostname
+	hostname = normalizeHostname(hostname)
+
+	var sessionToken string
+	var err error
+	if LoginMethod(opts.Method) == LoginMethodPassword {
+		sessionToken, err = loginWithPassword(cmd.Context(), hostname)
+	} else {
+		sessionToken, err = loginWithWeb(cmd.Context(), hostname, webLoginOptions{
+			// A browser on the remote host is of no use over SSH
+			NoBrowser: opts.NoBrowser || isSSHSession(),
+			Timeout:   opts.Timeout,
+		})
 	}
-
-	// Perform web login
-	sessionToken, err := loginWithWeb(hostname)
 	if err != nil {
 		logger.Error("%v", err)
 		return err
@@ -249,14 +376,19 @@ func loginMain(cmd *cobra.Command, opts *LoginCmdOpts) error {
 	apiClient.SetBaseURL(apiBaseURL)
 	apiClient.SetToken(sessionToken)
 
-	logger.Success("Device authorized")
+	if LoginMethod(opts.Method) == LoginMethodWeb {
+		logger.Success("Device authorized")
+	} else {
+		logger.Success("Authenticated")
+	}
 	fmt.Println()
 
 	// Get user information
 	var user *api.User
-	user, err = apiClient.GetUser()
+	user, err = apiClient.GetUser(cmd.Context())
 	if err != nil {
 		logger.Error("Failed to get user information: %v", err)
+		utils.LogErrorHint(err)
 		return err
 	}
 
@@ -268,15 +400,17 @@ func loginMain(cmd *cobra.Command, opts *LoginCmdOpts) error {
 	// Ensure OLM credentials exist and are valid
 	userID := user.UserID
 
-	orgID, err := utils.SelectOrgForm(apiClient, userID)
+	orgID, err := utils.SelectOrgForm(cmd.Context(), apiClient, userID)
 	if err != nil {
 		logger.Error("Failed to select organization: %v", err)
+		utils.LogErrorHint(err)
 		return err
 	}
 
-	newOlmCreds, err := apiClient.CreateOlm(userID, utils.GetDeviceName())
+	newOlmCreds, err := apiClient.CreateOlm(cmd.Context(), userID, utils.GetDeviceName())
 	if err != nil {
 		logger.Error("Failed to obtain olm credentials: %v", err)
+		utils.LogErrorHint(err)
 		return err
 	}
 
@@ -304,7 +438,7 @@ func loginMain(cmd *cobra.Command, opts *LoginCmdOpts) error {
 
 	// List and select organization
 	if user != nil {
-		if _, err := utils.SelectOrgForm(apiClient, user.UserID); err != nil {
+		if _, err := utils.SelectOrgForm(cmd.Context(), apiClient, user.UserID); err != nil {
 			logger.Warning("%v", err)
 		}
 	}
@@ -0,0 +1,131 @@
+package login
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/fosrl/cli/internal/api"
+	"github.com/fosrl/cli/internal/logger"
+)
+
+type LoginMethod string
+
+const (
+	LoginMethodWeb      LoginMethod = "web"
+	LoginMethodPassword LoginMethod = "password"
+)
+
+// maxCodeAttempts is how many times a two-factor code may be entered
+// before the login is aborted
+const maxCodeAttempts = 3
+
+// loginWithPassword prompts for email and password, and a two-factor
+// code if the server requests one, and returns the session token
+func loginWithPassword(ctx context.Context, hostname string) (string, error) {
+	// Create a temporary API client for login (without auth)
+	loginClient, err := api.NewClient(api.ClientConfig{
+		BaseURL:           hostname,
+		AgentName:         "pangolin-cli",
+		SessionCookieName: "p_session_token",
+		CSRFToken:         "x-csrf-protection",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	var email, password string
+
+	credentialsForm := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Email").
+				Value(&email).
+				Validate(func(s string) error {
+					if strings.TrimSpace(s) == "" {
+						return errors.New("email is required")
+					}
+					return nil
+				}),
+			huh.NewInput().
+				Title("Password").
+				EchoMode(huh.EchoModePassword).
+				Value(&password),
+		),
+	)
+
+	if err := credentialsForm.Run(); err != nil {
+		return "", err
+	}
+
+	req := api.LoginRequest{
+		Email:    strings.TrimSpace(email),
+		Password: password,
+	}
+
+	for attempt := 0; ; attempt++ {
+		loginResp, sessionToken, err := api.LoginWithCookie(ctx, loginClient, req)
+		if err != nil {
+			return "", fmt.Errorf("failed to log in: %w", err)
+		}
+
+		switch {
+		case loginResp.UseSecurityKey:
+			logger.Info("Security keys cannot be used from the terminal.")
+			logger.Info("Run 'pangolin login --method web' to log in through a browser instead.")
+			return "", errors.New("this account requires a security key")
+		case loginResp.TwoFactorSetupRequired:
+			logger.Info("Your organization requires two-factor authentication.")
+			logger.Info("Log in at %s/auth/login in a browser to set it up, then try again.", hostname)
+			return "", errors.New("two-factor authentication must be set up before logging in")
+		case loginResp.EmailVerificationRequired:
+			logger.Info("Check your inbox for the verification email, or log in at %s/auth/login in a browser to resend it.", hostname)
+			return "", errors.New("email address must be verified before logging in")
+		case loginResp.CodeRequested:
+			if attempt >= maxCodeAttempts {
+				return "", errors.New("too many invalid two-factor codes")
+			}
+			if req.Code != "" {
+				logger.Warning("Invalid two-factor code, please try again")
+			}
+
+			code, err := promptTwoFactorCode()
+			if err != nil {
+				return "", err
+			}
+			req.Code = code
+			continue
+		}
+
+		return sessionToken, nil
+	}
+}
+
+// promptTwoFactorCode asks for the code from the user's authenticator
+// app or one of their backup codes
+func promptTwoFactorCode() (string, error) {
+	var code string
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Two-factor code").
+				Description("Enter the code from your authenticator app or a backup code").
+				Value(&code).
+				Validate(func(s string) error {
+					if strings.TrimSpace(s) == "" {
+						return errors.New("code is required")
+					}
+					return nil
+				}),
+		),
+	)
+
+	if err := form.Run(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(code), nil
+}
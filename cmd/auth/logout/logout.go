@@ -30,9 +30,10 @@ func LogoutCmd() *cobra.Command {
 
 func logoutMain(cmd *cobra.Command) error {
 	apiClient := api.FromContext(cmd.Context())
+	cfg := config.ConfigFromContext(cmd.Context())
 
 	// Check if client is running before logout
-	olmClient := olm.NewClient("")
+	olmClient := olm.NewClient(cfg.SocketPath)
 	if olmClient.IsRunning() {
 		// Check that the client was started by this CLI by verifying the version
 		status, err := olmClient.GetStatus()
@@ -85,7 +86,7 @@ func logoutMain(cmd *cobra.Command) error {
 	}
 
 	// Check if there's an active session in the key store
-	accountStore, err := config.LoadAccountStore()
+	accountStore, err := config.LoadAccountStore(cfg)
 	if err != nil {
 		logger.Error("Failed to load account store: %s", err)
 		return err
@@ -97,7 +98,7 @@ func logoutMain(cmd *cobra.Command) error {
 	}
 
 	// Try to logout from server (client is always initialized)
-	if err := apiClient.Logout(); err != nil {
+	if err := apiClient.Logout(cmd.Context()); err != nil {
 		// Ignore logout errors - we'll still clear local data
 		logger.Debug("Failed to logout from server: %v", err)
 	}
@@ -119,7 +120,7 @@ func logoutMain(cmd *cobra.Command) error {
 	}
 
 	// Print logout message with account name
-	logger.Success("Logged out of Pangolin account %s", deletedAccount.Email)
+	logger.Success("Logged out of Pangolin account %s", deletedAccount.DisplayName())
 
 	return nil
 }
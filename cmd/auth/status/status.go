@@ -1,12 +1,14 @@
 package status
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/fosrl/cli/internal/api"
 	"github.com/fosrl/cli/internal/config"
 	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -36,13 +38,21 @@ func statusMain(cmd *cobra.Command) error {
 		return err
 	}
 
+	if account.IsAPIKey() {
+		return apiKeyStatus(cmd, apiClient, account)
+	}
+
 	// User info exists in config, try to get user from API
-	user, err := apiClient.GetUser()
+	user, err := apiClient.GetUser(cmd.Context())
 	if err != nil {
-		// Unable to get user - consider logged out (previously logged in but now not)
-		logger.Info("Status: logged out: %v", err)
-		logger.Info("Your session has expired or is invalid")
-		logger.Info("Run 'pangolin login' to authenticate again")
+		if errors.Is(err, api.ErrUnauthorized) {
+			// Session was rejected - consider logged out (previously logged in but now not)
+			logger.Info("Status: logged out: %v", err)
+		} else {
+			// The session may still be valid, but it could not be verified
+			logger.Info("Status: unknown: %v", err)
+		}
+		utils.LogErrorHint(err)
 		return err
 	}
 
@@ -71,3 +81,22 @@ func statusMain(cmd *cobra.Command) error {
 
 	return nil
 }
+
+// apiKeyStatus checks that the API key of the active account is still
+// accepted by fetching the organization it was registered for
+func apiKeyStatus(cmd *cobra.Command, apiClient *api.Client, account *config.Account) error {
+	if _, err := apiClient.GetOrg(cmd.Context(), account.OrgID); err != nil {
+		logger.Info("Status: API key rejected: %v", err)
+		utils.LogErrorHint(err)
+		return err
+	}
+
+	logger.Success("Status: logged in with API key")
+	logger.Info("@ %s", account.Host)
+	fmt.Println()
+
+	logger.Info("Key: %s", account.DisplayName())
+	logger.Info("Org ID: %s", account.OrgID)
+
+	return nil
+}
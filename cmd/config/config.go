@@ -0,0 +1,28 @@
+package configcmd
+
+import (
+	"github.com/fosrl/cli/cmd/config/edit"
+	"github.com/fosrl/cli/cmd/config/get"
+	"github.com/fosrl/cli/cmd/config/list"
+	"github.com/fosrl/cli/cmd/config/path"
+	"github.com/fosrl/cli/cmd/config/set"
+	"github.com/fosrl/cli/cmd/config/unset"
+	"github.com/spf13/cobra"
+)
+
+func ConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage CLI configuration",
+		Long:  "View and change the settings stored in the CLI configuration file",
+	}
+
+	cmd.AddCommand(get.GetCmd())
+	cmd.AddCommand(set.SetCmd())
+	cmd.AddCommand(unset.UnsetCmd())
+	cmd.AddCommand(list.ListCmd())
+	cmd.AddCommand(path.PathCmd())
+	cmd.AddCommand(edit.EditCmd())
+
+	return cmd
+}
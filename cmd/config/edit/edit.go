@@ -0,0 +1,85 @@
+package edit
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+func EditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Edit the configuration file",
+		Long:  "Open the configuration file in $VISUAL or $EDITOR and validate it afterwards",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := editMain(cmd); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func editMain(cmd *cobra.Command) error {
+	cfg := config.ConfigFromContext(cmd.Context())
+	path := cfg.Path()
+
+	// Make sure there is a file to edit
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := cfg.CreateFile(); err != nil {
+			logger.Error("Error: failed to create configuration file: %v", err)
+			return err
+		}
+	}
+
+	editor := editorCommand()
+	editorArgs := append(strings.Fields(editor), path)
+
+	editorCmd := exec.Command(editorArgs[0], editorArgs[1:]...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+
+	if err := editorCmd.Run(); err != nil {
+		logger.Error("Error: failed to run editor %s: %v", editor, err)
+		return err
+	}
+
+	// Load the file again to make sure the changes are valid
+	edited, err := config.LoadConfig()
+	if err == nil {
+		err = edited.Validate()
+	}
+	if err != nil {
+		logger.Error("Error: configuration is invalid: %v", err)
+		logger.Info("Run `pangolin config edit` again to fix it")
+		return err
+	}
+
+	logger.Success("Configuration saved")
+
+	return nil
+}
+
+// editorCommand returns the user's preferred editor
+func editorCommand() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editor
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+
+	return "vi"
+}
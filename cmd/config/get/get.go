@@ -0,0 +1,46 @@
+package get
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+func GetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "get <key>",
+		Short:             "Print a configuration value",
+		Long:              "Print the effective value of a configuration key",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cobra.FixedCompletions(config.KeyNames(), cobra.ShellCompDirectiveNoFileComp),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := getMain(cmd, args[0]); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func getMain(cmd *cobra.Command, key string) error {
+	cfg := config.ConfigFromContext(cmd.Context())
+
+	value, _, err := cfg.Value(key)
+	if err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	if list, ok := value.([]string); ok {
+		fmt.Println(strings.Join(list, ","))
+	} else {
+		fmt.Println(value)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package list
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+type ListCmdOpts struct {
+	JSON bool
+}
+
+// listEntry is a configuration value as printed by `config list --json`
+type listEntry struct {
+	Key         string             `json:"key"`
+	Value       any                `json:"value"`
+	Source      config.ValueSource `json:"source"`
+	Description string             `json:"description"`
+}
+
+func ListCmd() *cobra.Command {
+	opts := ListCmdOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all configuration values",
+		Long:  "List the effective value of every configuration key and where it came from: default, file, or environment variable",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listMain(cmd, &opts); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.JSON, "json", false, "Print values as JSON")
+
+	return cmd
+}
+
+func listMain(cmd *cobra.Command, opts *ListCmdOpts) error {
+	cfg := config.ConfigFromContext(cmd.Context())
+
+	var entries []listEntry
+	for _, key := range config.Keys() {
+		value, source, err := cfg.Value(key.Name)
+		if err != nil {
+			logger.Error("Error: %v", err)
+			return err
+		}
+
+		entries = append(entries, listEntry{
+			Key:         key.Name,
+			Value:       value,
+			Source:      source,
+			Description: key.Description,
+		})
+	}
+
+	if opts.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entries); err != nil {
+			logger.Error("Error: %v", err)
+			return err
+		}
+		return nil
+	}
+
+	headers := []string{"KEY", "VALUE", "SOURCE"}
+	rows := [][]string{}
+	for _, entry := range entries {
+		value := fmt.Sprint(entry.Value)
+		if list, ok := entry.Value.([]string); ok {
+			value = strings.Join(list, ",")
+		}
+
+		source := string(entry.Source)
+		if entry.Source == config.ValueSourceEnv {
+			key, _ := config.LookupKey(entry.Key)
+			source = fmt.Sprintf("env (%s)", key.EnvVar())
+		}
+
+		rows = append(rows, []string{entry.Key, value, source})
+	}
+	utils.PrintTable(headers, rows)
+
+	return nil
+}
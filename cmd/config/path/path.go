@@ -0,0 +1,23 @@
+package path
+
+import (
+	"fmt"
+
+	"github.com/fosrl/cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func PathCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "path",
+		Short: "Print the configuration file path",
+		Long:  "Print the path of the CLI configuration file",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg := config.ConfigFromContext(cmd.Context())
+			fmt.Println(cfg.Path())
+		},
+	}
+
+	return cmd
+}
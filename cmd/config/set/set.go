@@ -0,0 +1,79 @@
+package set
+
+import (
+	"errors"
+	"os"
+
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+func SetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <key> <value> [<key> <value>...]",
+		Short: "Set configuration values",
+		Long: `Validate and save values for configuration keys.
+
+List values are given as a comma-separated string. Keys that must be set
+together, such as client_cert and client_key, are given in one invocation:
+
+  pangolin config set client_cert cert.pem client_key key.pem`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 || len(args)%2 != 0 {
+				return errors.New("requires key and value pairs")
+			}
+			return nil
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args)%2 == 0 {
+				return config.KeyNames(), cobra.ShellCompDirectiveNoFileComp
+			}
+
+			if key, err := config.LookupKey(args[len(args)-1]); err == nil {
+				switch {
+				case len(key.Allowed) > 0:
+					return key.Allowed, cobra.ShellCompDirectiveNoFileComp
+				case key.Type == config.KeyTypeBool:
+					return []string{"true", "false"}, cobra.ShellCompDirectiveNoFileComp
+				}
+			}
+			return nil, cobra.ShellCompDirectiveDefault
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := setMain(cmd, args); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func setMain(cmd *cobra.Command, args []string) error {
+	cfg := config.ConfigFromContext(cmd.Context())
+
+	values := map[string]string{}
+	for i := 0; i < len(args); i += 2 {
+		values[args[i]] = args[i+1]
+	}
+
+	if err := cfg.SetValues(values); err != nil {
+		logger.Error("Error: %v", err)
+		if errors.Is(err, config.ErrClientCertKeyPair) {
+			logger.Info("Set both in one command: pangolin config set client_cert <file> client_key <file>")
+		}
+		return err
+	}
+
+	for i := 0; i < len(args); i += 2 {
+		name := args[i]
+		logger.Success("Set %s to %s", name, args[i+1])
+
+		if key, err := config.LookupKey(name); err == nil && os.Getenv(key.EnvVar()) != "" {
+			logger.Warning("%s is set and overrides this value", key.EnvVar())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package unset
+
+import (
+	"errors"
+	"os"
+
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+func UnsetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "unset <key>...",
+		Short:             "Reset configuration values to their defaults",
+		Long:              "Remove configuration keys from the configuration file, so that their default values apply",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: cobra.FixedCompletions(config.KeyNames(), cobra.ShellCompDirectiveNoFileComp),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := unsetMain(cmd, args); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func unsetMain(cmd *cobra.Command, names []string) error {
+	cfg := config.ConfigFromContext(cmd.Context())
+
+	if err := cfg.UnsetValues(names...); err != nil {
+		logger.Error("Error: %v", err)
+		if errors.Is(err, config.ErrClientCertKeyPair) {
+			logger.Info("Unset both in one command: pangolin config unset client_cert client_key")
+		}
+		return err
+	}
+
+	for _, name := range names {
+		logger.Success("Unset %s", name)
+	}
+
+	return nil
+}
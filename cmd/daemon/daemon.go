@@ -0,0 +1,116 @@
+package daemoncmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"os/user"
+	"strconv"
+	"syscall"
+
+	"github.com/fosrl/cli/internal/daemon"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+type DaemonCmdOpts struct {
+	SocketPath   string
+	AllowUsers   []string
+	ExitWhenIdle bool
+	LogFile      string
+}
+
+func DaemonCmd() *cobra.Command {
+	opts := DaemonCmdOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run the privileged client daemon",
+		Long: `Run the daemon that starts and stops the client as root on behalf of
+unprivileged invocations of the CLI.
+
+The daemon must run as root. Only root and the allowed users can control it.
+When started through sudo, the invoking user is allowed by default.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := daemonMain(cmd, &opts); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.SocketPath, "listen", daemon.DefaultSocketPath, "Unix socket `path` to listen on")
+	cmd.Flags().StringSliceVar(&opts.AllowUsers, "allow-user", nil, "`User` name or ID allowed to control the daemon (repeatable, default: the user running sudo)")
+	cmd.Flags().BoolVar(&opts.ExitWhenIdle, "idle-exit", false, "Exit once the client started by the daemon has stopped")
+	cmd.Flags().StringVar(&opts.LogFile, "log-file", "", "Append the daemon output to `file` instead of writing it to the terminal")
+
+	return cmd
+}
+
+func daemonMain(cmd *cobra.Command, opts *DaemonCmdOpts) error {
+	allowUsers := opts.AllowUsers
+	if !cmd.Flags().Changed("allow-user") {
+		if sudoUID := os.Getenv("SUDO_UID"); sudoUID != "" {
+			allowUsers = []string{sudoUID}
+		}
+	}
+
+	allowedUIDs := make([]uint32, 0, len(allowUsers))
+	for _, name := range allowUsers {
+		uid, err := lookupUID(name)
+		if err != nil {
+			logger.Error("Error: %v", err)
+			return err
+		}
+		allowedUIDs = append(allowedUIDs, uid)
+	}
+
+	// The daemon outlives the terminal it was started from
+	signal.Ignore(syscall.SIGHUP)
+
+	if opts.LogFile != "" {
+		logFile, err := os.OpenFile(opts.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			logger.Error("Error: failed to open log file: %v", err)
+			return err
+		}
+		defer logFile.Close()
+
+		os.Stdout = logFile
+		os.Stderr = logFile
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := daemon.Serve(ctx, daemon.ServerConfig{
+		SocketPath:   opts.SocketPath,
+		AllowedUIDs:  allowedUIDs,
+		ExitWhenIdle: opts.ExitWhenIdle,
+	})
+	if err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// lookupUID returns the user ID of the user with the given name or ID
+func lookupUID(name string) (uint32, error) {
+	if uid, err := strconv.ParseUint(name, 10, 32); err == nil {
+		return uint32(uid), nil
+	}
+
+	u, err := user.Lookup(name)
+	if err != nil {
+		return 0, fmt.Errorf("unknown user %q", name)
+	}
+
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("user %q has no numeric user ID", name)
+	}
+
+	return uint32(uid), nil
+}
@@ -2,43 +2,136 @@ package client
 
 import (
 	"errors"
+	"fmt"
 	"os"
 
 	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/daemon"
+	"github.com/fosrl/cli/internal/hooks"
 	"github.com/fosrl/cli/internal/logger"
 	"github.com/fosrl/cli/internal/olm"
+	"github.com/fosrl/cli/internal/service"
 	"github.com/fosrl/cli/internal/tui"
 	"github.com/spf13/cobra"
 )
 
+// errForeignClient is returned for clients that were not started by this
+// CLI, which it does not stop
+var errForeignClient = errors.New("client was not started by Pangolin CLI")
+
+type ClientDownCmdOpts struct {
+	Name    string
+	All     bool
+	NoHooks bool
+}
+
 func ClientDownCmd() *cobra.Command {
+	opts := ClientDownCmdOpts{}
+
 	cmd := &cobra.Command{
 		Use:   "client",
 		Short: "Stop the client connection",
 		Long:  "Stop the currently running client connection",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if opts.All && opts.Name != "" {
+				return errors.New("--name and --all options conflict")
+			}
+			return nil
+		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := clientDownMain(cmd); err != nil {
+			if err := clientDownMain(cmd, &opts); err != nil {
 				os.Exit(1)
 			}
 		},
 	}
 
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Name of the client instance to stop (default: the default instance)")
+	cmd.Flags().BoolVar(&opts.All, "all", false, "Stop every running client instance")
+	cmd.Flags().BoolVar(&opts.NoHooks, "no-hooks", false, "Do not run the connection hooks")
+
 	return cmd
 }
 
-func clientDownMain(cmd *cobra.Command) error {
+func clientDownMain(cmd *cobra.Command, opts *ClientDownCmdOpts) error {
 	cfg := config.ConfigFromContext(cmd.Context())
 
-	// Get socket path from config or use default
-	client := olm.NewClient("")
+	if !opts.All {
+		instance, err := cfg.Instance(opts.Name)
+		if err != nil {
+			logger.Error("Error: %v", err)
+			return err
+		}
+
+		return stopInstance(cfg, instance, opts)
+	}
+
+	instances, err := cfg.Instances()
+	if err != nil {
+		logger.Error("Error: failed to list instances: %v", err)
+		return err
+	}
+
+	// Every instance is stopped even if stopping one fails. Clients not
+	// started by this CLI are skipped.
+	var stopErr error
+	stopped := 0
+	skipped := 0
+	for _, instance := range instances {
+		if !olm.NewClient(instance.SocketPath).IsRunning() {
+			continue
+		}
+
+		if err := stopInstance(cfg, instance, opts); err != nil {
+			if errors.Is(err, errForeignClient) {
+				skipped++
+				continue
+			}
+			stopErr = err
+			continue
+		}
+		stopped++
+	}
+
+	if stopErr == nil && stopped == 0 {
+		err := errors.New("no client is currently running")
+		if skipped > 0 {
+			err = errors.New("no client started by Pangolin CLI is currently running")
+		}
+		logger.Info("Error: %v", err)
+		return err
+	}
+
+	return stopErr
+}
+
+// stopInstance stops the client of the instance and shows its log until
+// it has exited
+func stopInstance(cfg *config.Config, instance config.Instance, opts *ClientDownCmdOpts) error {
+	client := olm.NewClient(instance.SocketPath)
+	if err := client.CheckSocket(); err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
 
 	// Check if client is running
 	if !client.IsRunning() {
 		err := errors.New("no client is currently running")
+		if instance.Name != "" {
+			err = fmt.Errorf("no client is currently running for instance %s", instance.Name)
+		}
 		logger.Info("Error: %v", err)
 		return err
 	}
 
+	// Stopping the client managed by systemd would only last until
+	// the unit is started again. The unit runs the default instance.
+	if instance.Name == "" && service.IsActive() {
+		err := fmt.Errorf("the client is managed by the %s systemd unit", service.UnitName)
+		logger.Error("Error: %v", err)
+		logger.Info("Run `sudo systemctl stop %s` to stop it until the next boot, or `sudo pangolin service uninstall` to remove it", service.UnitName)
+		return err
+	}
+
 	// Check that the client was started by this CLI by verifying the version
 	status, err := client.GetStatus()
 	if err != nil {
@@ -49,20 +142,58 @@ func clientDownMain(cmd *cobra.Command) error {
 	if status.Agent != olm.AgentName {
 		logger.Error("Client was not started by Pangolin CLI (version: %s)", status.Version)
 		logger.Info("Only clients started by this CLI can be stopped using this command")
-		return err
+		return errForeignClient
 	}
 
-	// Send exit signal
-	exitResp, err := client.Exit()
-	if err != nil {
-		logger.Error("Error: %v", err)
-		return err
+	conn := hooks.Connection{
+		Instance:      instance.Name,
+		OrgID:         status.OrgID,
+		InterfaceName: instance.InterfaceName,
+		Peers:         status.PeerStatuses,
+	}
+
+	daemonClient := daemon.NewClient("")
+	daemonStatus, err := daemonClient.Status(instance.Name)
+	managedByDaemon := err == nil && daemonStatus.Running
+	if managedByDaemon {
+		conn.Endpoint = daemonStatus.Endpoint
+		if daemonStatus.InterfaceName != "" {
+			conn.InterfaceName = daemonStatus.InterfaceName
+		}
+	}
+
+	if !opts.NoHooks {
+		hooks.Run(cfg.Hooks, hooks.PreDown, conn)
+	}
+
+	// Stop the client through the daemon when it manages the client,
+	// otherwise send the exit signal to the client directly
+	var exitStatus string
+	if managedByDaemon {
+		if err := daemonClient.Stop(instance.Name); err != nil {
+			logger.Error("Error: %v", err)
+			return err
+		}
+		exitStatus = "stopping"
+	} else {
+		exitResp, err := client.Exit()
+		if err != nil {
+			logger.Error("Error: %v", err)
+			return err
+		}
+		exitStatus = exitResp.Status
 	}
 
 	// Show log preview until process stops
+	header := "Shutting down client..."
+	if instance.Name != "" {
+		header = fmt.Sprintf("Shutting down client %s...", instance.Name)
+	}
+
 	completed, err := tui.NewLogPreview(tui.LogPreviewConfig{
-		LogFile: cfg.LogFile,
-		Header:  "Shutting down client...",
+		LogFile:    instance.LogFile,
+		SocketPath: instance.SocketPath,
+		Header:     header,
 		ExitCondition: func(client *olm.Client, status *olm.StatusResponse) (bool, bool) {
 			// Exit when process is no longer running (socket doesn't exist)
 			if !client.IsRunning() {
@@ -84,8 +215,13 @@ func clientDownMain(cmd *cobra.Command) error {
 
 	if completed {
 		logger.Success("Client shutdown completed")
+
+		if !opts.NoHooks {
+			conn.Peers = nil
+			hooks.Run(cfg.Hooks, hooks.PostDown, conn)
+		}
 	} else {
-		logger.Info("Client shutdown initiated: %s", exitResp.Status)
+		logger.Info("Client shutdown initiated: %s", exitStatus)
 	}
 
 	return nil
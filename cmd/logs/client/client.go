@@ -17,6 +17,7 @@ import (
 type ClientLogsCmdOpts struct {
 	Follow bool
 	Lines  int
+	Name   string
 }
 
 func ClientLogsCmd() *cobra.Command {
@@ -34,6 +35,7 @@ func ClientLogsCmd() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "Follow log output (like tail -f)")
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Name of the client instance to show logs of (default: the default instance)")
 	cmd.Flags().IntVarP(&opts.Lines, "lines", "n", 0, "Number of lines to show (0 = all lines, only used with -f to show lines before following)")
 
 	return cmd
@@ -42,9 +44,15 @@ func ClientLogsCmd() *cobra.Command {
 func clientLogsMain(cmd *cobra.Command, opts *ClientLogsCmdOpts) error {
 	cfg := config.ConfigFromContext(cmd.Context())
 
+	instance, err := cfg.Instance(opts.Name)
+	if err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
+
 	if opts.Follow {
 		// Follow the log file
-		if err := watchLogFile(cfg.LogFile, opts.Lines); err != nil {
+		if err := watchLogFile(instance.LogFile, opts.Lines); err != nil {
 			logger.Error("Error: %v", err)
 			return err
 		}
@@ -55,13 +63,13 @@ func clientLogsMain(cmd *cobra.Command, opts *ClientLogsCmdOpts) error {
 	// Just print the current log file contents
 	if opts.Lines > 0 {
 		// Show last N lines
-		if err := printLastLines(cfg.LogFile, opts.Lines); err != nil {
+		if err := printLastLines(instance.LogFile, opts.Lines); err != nil {
 			logger.Error("Error: %v", err)
 			return err
 		}
 	} else {
 		// Show all lines
-		if err := printLogFile(cfg.LogFile); err != nil {
+		if err := printLogFile(instance.LogFile); err != nil {
 			logger.Error("Error: %v", err)
 			return err
 		}
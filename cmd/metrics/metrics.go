@@ -0,0 +1,18 @@
+package metrics
+
+import (
+	"github.com/fosrl/cli/cmd/metrics/serve"
+	"github.com/spf13/cobra"
+)
+
+func MetricsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "metrics",
+		Short: "Client metrics",
+		Long:  "Export client status metrics for monitoring",
+	}
+
+	cmd.AddCommand(serve.MetricsServeCmd())
+
+	return cmd
+}
@@ -0,0 +1,62 @@
+package serve
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/metrics"
+	"github.com/fosrl/cli/internal/olm"
+	"github.com/spf13/cobra"
+)
+
+type MetricsServeCmdOpts struct {
+	Listen string
+	Name   string
+}
+
+func MetricsServeCmd() *cobra.Command {
+	opts := MetricsServeCmdOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve client metrics for Prometheus",
+		Long: `Serve the status of the client as Prometheus metrics on /metrics.
+
+The status is read from the client when scraped. The exporter keeps running
+while the client is down and reports pangolin_client_up 0.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := metricsServeMain(cmd, &opts); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Listen, "listen", ":9273", "`Address` to serve metrics on")
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Name of the client instance to export (default: the default instance)")
+
+	return cmd
+}
+
+func metricsServeMain(cmd *cobra.Command, opts *MetricsServeCmdOpts) error {
+	cfg := config.ConfigFromContext(cmd.Context())
+
+	instance, err := cfg.Instance(opts.Name)
+	if err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := metrics.Serve(ctx, opts.Listen, olm.NewClient(instance.SocketPath)); err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	return nil
+}
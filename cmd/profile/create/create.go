@@ -0,0 +1,84 @@
+package create
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+type CreateCmdOpts struct {
+	Force bool
+}
+
+func CreateCmd() *cobra.Command {
+	opts := CreateCmdOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a connection profile",
+		Long: `Create a connection profile from the given options.
+
+Options use the same flags as ` + "`pangolin up client`" + `. When the profile is
+used, flags given explicitly to ` + "`up client`" + ` still take precedence.`,
+		Example: "  pangolin profile create office --org my-org --mtu 1380 --upstream-dns 1.1.1.1,9.9.9.9",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := createMain(cmd, &opts, args[0]); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	for _, key := range config.ProfileKeys() {
+		cmd.Flags().String(key.FlagName(), "", key.Description)
+	}
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Replace an existing profile with the same name")
+
+	return cmd
+}
+
+func createMain(cmd *cobra.Command, opts *CreateCmdOpts, name string) error {
+	cfg := config.ConfigFromContext(cmd.Context())
+
+	if _, err := cfg.Profile(name); err == nil && !opts.Force {
+		err := fmt.Errorf("profile %q already exists", name)
+		logger.Error("Error: %v", err)
+		logger.Info("Use --force to replace it")
+		return err
+	}
+
+	profile := config.Profile{}
+	for _, key := range config.ProfileKeys() {
+		if !cmd.Flags().Changed(key.FlagName()) {
+			continue
+		}
+
+		raw, _ := cmd.Flags().GetString(key.FlagName())
+		value, err := key.Parse(raw)
+		if err != nil {
+			logger.Error("Error: %v", err)
+			return err
+		}
+		profile[key.Name] = value
+	}
+
+	if len(profile) == 0 {
+		err := fmt.Errorf("no options given for profile %q", name)
+		logger.Error("Error: %v", err)
+		logger.Info("Run `pangolin profile create --help` to see the available options")
+		return err
+	}
+
+	if err := cfg.SaveProfile(name, profile); err != nil {
+		logger.Error("Error: failed to save profile: %v", err)
+		return err
+	}
+
+	logger.Success("Saved profile %s", name)
+	logger.Info("Use it with `pangolin up client --profile %s`", name)
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package delete
+
+import (
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+func DeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a connection profile",
+		Long:  "Delete a connection profile",
+		Args:  cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+
+			cfg := config.ConfigFromContext(cmd.Context())
+			return slices.Sorted(maps.Keys(cfg.Profiles)), cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := deleteMain(cmd, args[0]); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func deleteMain(cmd *cobra.Command, name string) error {
+	cfg := config.ConfigFromContext(cmd.Context())
+
+	if err := cfg.DeleteProfile(name); err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	logger.Success("Deleted profile %s", name)
+
+	return nil
+}
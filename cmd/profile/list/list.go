@@ -0,0 +1,54 @@
+package list
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func ListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List connection profiles",
+		Long:  "List all connection profiles",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listMain(cmd); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func listMain(cmd *cobra.Command) error {
+	cfg := config.ConfigFromContext(cmd.Context())
+
+	if len(cfg.Profiles) == 0 {
+		logger.Info("No profiles found. Create one with `pangolin profile create <name>`")
+		return nil
+	}
+
+	headers := []string{"NAME", "ORG", "OPTIONS"}
+	rows := [][]string{}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Profiles)) {
+		profile := cfg.Profiles[name]
+
+		org := "-"
+		if value, ok := profile["org"]; ok {
+			org = config.FormatProfileValue(value)
+		}
+
+		rows = append(rows, []string{name, org, fmt.Sprintf("%d", len(profile))})
+	}
+	utils.PrintTable(headers, rows)
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package profile
+
+import (
+	"github.com/fosrl/cli/cmd/profile/create"
+	"github.com/fosrl/cli/cmd/profile/delete"
+	"github.com/fosrl/cli/cmd/profile/list"
+	"github.com/fosrl/cli/cmd/profile/show"
+	"github.com/spf13/cobra"
+)
+
+func ProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage connection profiles",
+		Long:  "Manage named sets of options for `pangolin up client --profile`",
+	}
+
+	cmd.AddCommand(list.ListCmd())
+	cmd.AddCommand(show.ShowCmd())
+	cmd.AddCommand(create.CreateCmd())
+	cmd.AddCommand(delete.DeleteCmd())
+
+	return cmd
+}
@@ -0,0 +1,54 @@
+package show
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+func ShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "show <name>",
+		Short:             "Show a connection profile",
+		Long:              "Show the options stored in a connection profile",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProfileName,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := showMain(cmd, args[0]); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func showMain(cmd *cobra.Command, name string) error {
+	cfg := config.ConfigFromContext(cmd.Context())
+
+	profile, err := cfg.Profile(name)
+	if err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(profile)) {
+		fmt.Printf("%s: %s\n", key, config.FormatProfileValue(profile[key]))
+	}
+
+	return nil
+}
+
+func completeProfileName(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cfg := config.ConfigFromContext(cmd.Context())
+	return slices.Sorted(maps.Keys(cfg.Profiles)), cobra.ShellCompDirectiveNoFileComp
+}
@@ -2,16 +2,24 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 
 	"github.com/fosrl/cli/cmd/auth"
 	"github.com/fosrl/cli/cmd/auth/login"
 	"github.com/fosrl/cli/cmd/auth/logout"
+	configcmd "github.com/fosrl/cli/cmd/config"
+	daemoncmd "github.com/fosrl/cli/cmd/daemon"
 	"github.com/fosrl/cli/cmd/down"
 	"github.com/fosrl/cli/cmd/logs"
+	"github.com/fosrl/cli/cmd/metrics"
+	"github.com/fosrl/cli/cmd/profile"
 	selectcmd "github.com/fosrl/cli/cmd/select"
+	servicecmd "github.com/fosrl/cli/cmd/service"
 	"github.com/fosrl/cli/cmd/status"
 	"github.com/fosrl/cli/cmd/up"
 	"github.com/fosrl/cli/cmd/update"
@@ -19,6 +27,7 @@ import (
 	"github.com/fosrl/cli/internal/api"
 	"github.com/fosrl/cli/internal/config"
 	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/utils"
 	versionpkg "github.com/fosrl/cli/internal/version"
 	"github.com/spf13/cobra"
 )
@@ -41,16 +50,31 @@ func RootCommand(initResources bool) (*cobra.Command, error) {
 	}
 
 	cmd.AddCommand(auth.AuthCommand())
+	cmd.AddCommand(configcmd.ConfigCmd())
+	cmd.AddCommand(profile.ProfileCmd())
 	cmd.AddCommand(selectcmd.SelectCmd())
 	cmd.AddCommand(up.UpCmd())
+	cmd.AddCommand(daemoncmd.DaemonCmd())
+	cmd.AddCommand(servicecmd.ServiceCmd())
 	cmd.AddCommand(down.DownCmd())
 	cmd.AddCommand(logs.LogsCmd())
 	cmd.AddCommand(status.StatusCmd())
+	cmd.AddCommand(metrics.MetricsCmd())
 	cmd.AddCommand(update.UpdateCmd())
 	cmd.AddCommand(version.VersionCmd())
 	cmd.AddCommand(login.LoginCmd())
 	cmd.AddCommand(logout.LogoutCmd())
 
+	flags := cmd.PersistentFlags()
+	flags.String("https-proxy", "", "Proxy `URL` for HTTPS requests (overrides HTTPS_PROXY)")
+	flags.String("no-proxy", "", "Comma-separated `hosts` that bypass the proxy (overrides NO_PROXY)")
+	flags.StringSlice("ca-file", nil, "Additional trusted CA certificate `file` in PEM format (repeatable)")
+	flags.String("client-cert", "", "Client certificate `file` in PEM format for mutual TLS")
+	flags.String("client-key", "", "Client private key `file` in PEM format for mutual TLS")
+	flags.Bool("insecure-skip-verify", false, "Skip TLS certificate verification (unsafe, for lab hosts only)")
+	flags.String("socket", "", "Client control socket `path` (overrides socket_path)")
+	flags.Bool("trace-http", false, "Log HTTP requests and responses with secrets redacted (implies debug logging)")
+
 	if !initResources {
 		return cmd, nil
 	}
@@ -60,27 +84,44 @@ func RootCommand(initResources bool) (*cobra.Command, error) {
 		return nil, err
 	}
 
-	if err := cfg.Validate(); err != nil {
-		return nil, err
+	// The configuration is validated before its log level is used. An
+	// invalid configuration keeps the default log level and is reported
+	// once the command runs, so that the config commands can fix it.
+	if err := cfg.Validate(); err == nil {
+		logger.InitLogger(cfg.LogLevel)
 	}
 
-	accountStore, err := config.LoadAccountStore()
+	accountStore, err := config.LoadAccountStore(cfg)
 	if err != nil {
 		return nil, err
 	}
 
+	// An API key from the environment takes precedence over any
+	// stored account, for headless use in automation.
+	if envAPIKey := os.Getenv(config.APIKeyEnvVar); envAPIKey != "" {
+		host := utils.FormatHostnameBaseURL(os.Getenv(config.HostEnvVar))
+		accountStore.UseEnvAccount(config.NewAPIKeyAccount(host, envAPIKey, os.Getenv(config.OrgEnvVar)))
+	}
+
 	var apiBaseURL string
 	var sessionToken string
+	var apiKey string
 
 	if activeAccount, _ := accountStore.ActiveAccount(); activeAccount != nil {
 		apiBaseURL = activeAccount.Host
-		sessionToken = activeAccount.SessionToken
-	} else {
-		apiBaseURL = ""
-		sessionToken = ""
+		if activeAccount.IsAPIKey() {
+			apiKey = activeAccount.APIKey
+		} else {
+			sessionToken = activeAccount.SessionToken
+		}
 	}
 
-	client, err := api.InitClient(apiBaseURL, sessionToken)
+	var client *api.Client
+	if apiKey != "" {
+		client, err = api.InitClientWithAPIKey(apiBaseURL, apiKey)
+	} else {
+		client, err = api.InitClient(apiBaseURL, sessionToken)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -98,6 +139,35 @@ func RootCommand(initResources bool) (*cobra.Command, error) {
 func mainCommandPreRun(cmd *cobra.Command, args []string) error {
 	cfg := config.ConfigFromContext(cmd.Context())
 
+	// The config commands must keep working with an invalid
+	// configuration, since they are used to fix it.
+	if isConfigCommand(cmd) {
+		return nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		logger.Error("Error: invalid configuration: %v", err)
+		logger.Info("Run `pangolin config list` to review your configuration")
+		return err
+	}
+
+	if traceHTTP, _ := cmd.Flags().GetBool("trace-http"); traceHTTP {
+		logger.InitLogger(logger.LogLevelDebug)
+		api.SetTraceBodies(true)
+	}
+
+	// Network settings apply to every command, including the
+	// update command which checks for the latest release.
+	if err := applyNetworkConfig(cmd, cfg); err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	if err := applySocketPath(cmd, cfg); err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
+
 	// Skip init/update check for version and update commands
 	// Check both the command name and if it's one of these specific commands
 	cmdName := cmd.Name()
@@ -119,6 +189,84 @@ func mainCommandPreRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// isConfigCommand reports whether cmd is part of the config command group
+func isConfigCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == "config" && c.Parent() != nil && !c.Parent().HasParent() {
+			return true
+		}
+	}
+	return false
+}
+
+// applySocketPath overrides the path of the client control socket with
+// the --socket flag. The path is made absolute, since it is also passed
+// on to the daemon.
+func applySocketPath(cmd *cobra.Command, cfg *config.Config) error {
+	if !cmd.Flags().Changed("socket") {
+		return nil
+	}
+
+	socketPath, _ := cmd.Flags().GetString("socket")
+	if socketPath == "" {
+		return errors.New("--socket must not be empty")
+	}
+
+	absPath, err := filepath.Abs(socketPath)
+	if err != nil {
+		return fmt.Errorf("invalid socket path: %w", err)
+	}
+	cfg.SocketPath = absPath
+
+	return nil
+}
+
+// applyNetworkConfig merges the network flags into the configuration and
+// installs the resulting transport for all outgoing HTTP requests.
+func applyNetworkConfig(cmd *cobra.Command, cfg *config.Config) error {
+	flags := cmd.Flags()
+
+	if flags.Changed("https-proxy") {
+		cfg.HTTPSProxy, _ = flags.GetString("https-proxy")
+	}
+	if flags.Changed("no-proxy") {
+		cfg.NoProxy, _ = flags.GetString("no-proxy")
+	}
+	if flags.Changed("ca-file") {
+		cfg.CAFiles, _ = flags.GetStringSlice("ca-file")
+	}
+	if flags.Changed("client-cert") {
+		cfg.ClientCert, _ = flags.GetString("client-cert")
+	}
+	if flags.Changed("client-key") {
+		cfg.ClientKey, _ = flags.GetString("client-key")
+	}
+	if flags.Changed("insecure-skip-verify") {
+		cfg.InsecureSkipVerify, _ = flags.GetBool("insecure-skip-verify")
+	}
+
+	transport, err := api.NewTransport(api.TransportConfig{
+		HTTPSProxy:         cfg.HTTPSProxy,
+		NoProxy:            cfg.NoProxy,
+		CAFiles:            cfg.CAFiles,
+		ClientCert:         cfg.ClientCert,
+		ClientKey:          cfg.ClientKey,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	})
+	if err != nil {
+		return err
+	}
+
+	if cfg.InsecureSkipVerify {
+		logger.Warning("TLS certificate verification is disabled")
+	}
+
+	api.SetDefaultTransport(transport)
+	versionpkg.SetTransport(api.NewTraceTransport(transport))
+
+	return nil
+}
+
 // Make sure all required directories exist once
 // before executing any subcommands.
 func ensureRuntimeDirs(cfg *config.Config) {
@@ -150,7 +298,18 @@ func Execute() {
 		os.Exit(1)
 	}
 
-	if err := cmd.Execute(); err != nil {
+	// Cancel the command context on the first interrupt so that
+	// in-flight API requests are aborted. The default signal behavior
+	// is restored afterwards, so a second interrupt terminates the
+	// process immediately.
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
 		os.Exit(1)
 	}
 }
@@ -42,6 +42,7 @@ func AccountCmd() *cobra.Command {
 
 func accountMain(cmd *cobra.Command, opts *AccountCmdOpts) error {
 	accountStore := config.AccountStoreFromContext(cmd.Context())
+	cfg := config.ConfigFromContext(cmd.Context())
 
 	if len(accountStore.Accounts) == 0 {
 		err := errors.New("not logged in")
@@ -59,7 +60,7 @@ func accountMain(cmd *cobra.Command, opts *AccountCmdOpts) error {
 				continue
 			}
 
-			if opts.Account == account.Email {
+			if opts.Account == account.Email || opts.Account == account.DisplayName() {
 				selectedAccount = &account
 				break
 			}
@@ -87,8 +88,12 @@ func accountMain(cmd *cobra.Command, opts *AccountCmdOpts) error {
 		return err
 	}
 
+	if accountStore.EnvAccount() != nil {
+		logger.Warning("%s is set and overrides the selected account", config.APIKeyEnvVar)
+	}
+
 	// Check if olmClient is running and if we need to shut it down
-	olmClient := olm.NewClient("")
+	olmClient := olm.NewClient(cfg.SocketPath)
 	if olmClient.IsRunning() {
 		logger.Info("Shutting down running client")
 		_, err := olmClient.Exit()
@@ -97,7 +102,7 @@ func accountMain(cmd *cobra.Command, opts *AccountCmdOpts) error {
 		}
 	}
 
-	selectedAccountStr := fmt.Sprintf("%s @ %s", selectedAccount.Email, selectedAccount.Host)
+	selectedAccountStr := fmt.Sprintf("%s @ %s", selectedAccount.DisplayName(), selectedAccount.Host)
 	logger.Success("Successfully selected account: %s", selectedAccountStr)
 
 	return nil
@@ -132,7 +137,7 @@ func selectAccountForm(accounts map[string]config.Account, hostFilter string) (*
 
 	var orgOptions []huh.Option[accountOption]
 	for _, account := range filteredAccounts {
-		label := fmt.Sprintf("%s @ %s", account.Email, account.Host)
+		label := fmt.Sprintf("%s @ %s", account.DisplayName(), account.Host)
 		orgOptions = append(orgOptions, huh.NewOption(label, accountOption{
 			Account: account,
 			Label:   label,
@@ -15,8 +15,8 @@ func completeAccountFlag(cmd *cobra.Command, args []string, toComplete string) (
 	candidateSet := make(map[string]struct{})
 
 	for _, v := range accountStore.Accounts {
-		if strings.HasPrefix(v.Email, toComplete) {
-			candidateSet[v.Email] = struct{}{}
+		if name := v.DisplayName(); strings.HasPrefix(name, toComplete) {
+			candidateSet[name] = struct{}{}
 		}
 	}
 
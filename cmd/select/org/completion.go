@@ -18,13 +18,12 @@ func completeOrgID(cmd *cobra.Command, args []string, toComplete string) ([]stri
 		return []string{}, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	orgsResponse, err := apiClient.ListUserOrgs(activeAccount.UserID)
-	if err != nil {
-		return []string{}, cobra.ShellCompDirectiveNoFileComp
-	}
-
 	var candidates []string
-	for _, org := range orgsResponse.Orgs {
+	for org, err := range apiClient.UserOrgs(cmd.Context(), activeAccount.UserID) {
+		if err != nil {
+			return []string{}, cobra.ShellCompDirectiveNoFileComp
+		}
+
 		if strings.HasPrefix(org.OrgID, toComplete) {
 			candidates = append(candidates, fmt.Sprintf("%s\t%s", org.OrgID, org.Name))
 		}
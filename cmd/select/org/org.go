@@ -1,6 +1,7 @@
 package org
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -51,20 +52,44 @@ func orgMain(cmd *cobra.Command, opts *OrgCmdOpts) error {
 	}
 	userID := activeAccount.UserID
 
+	// The account of an API key from the environment is never saved,
+	// so its organization can only be changed through the environment
+	if accountStore.EnvAccount() != nil {
+		err := fmt.Errorf("the organization cannot be selected while %s is set", config.APIKeyEnvVar)
+		logger.Error("Error: %v", err)
+		logger.Info("Set %s or pass --org to the command instead", config.OrgEnvVar)
+		return err
+	}
+
 	var selectedOrgID string
 
-	// Check if --org-id flag is provided
-	if opts.OrgID != "" {
-		// Validate that the org exists
-		orgsResp, err := apiClient.ListUserOrgs(userID)
-		if err != nil {
-			logger.Error("Failed to list organizations: %v", err)
+	// API keys cannot list organizations, so the organization
+	// must be given explicitly and is validated directly
+	if activeAccount.IsAPIKey() {
+		if opts.OrgID == "" {
+			err := errors.New("--org is required when using an API key account")
+			logger.Error("Error: %v", err)
 			return err
 		}
 
-		// Check if the provided orgId exists in the user's organizations
+		if _, err := apiClient.GetOrg(cmd.Context(), opts.OrgID); err != nil {
+			logger.Error("Failed to get organization: %v", err)
+			utils.LogErrorHint(err)
+			return err
+		}
+
+		selectedOrgID = opts.OrgID
+	} else if opts.OrgID != "" {
+		// Validate that the org exists by checking if the provided
+		// orgId is one of the user's organizations
 		orgExists := false
-		for _, org := range orgsResp.Orgs {
+		for org, err := range apiClient.UserOrgs(cmd.Context(), userID) {
+			if err != nil {
+				logger.Error("Failed to list organizations: %v", err)
+				utils.LogErrorHint(err)
+				return err
+			}
+
 			if org.OrgID == opts.OrgID {
 				orgExists = true
 				break
@@ -81,9 +106,10 @@ func orgMain(cmd *cobra.Command, opts *OrgCmdOpts) error {
 		selectedOrgID = opts.OrgID
 	} else {
 		// No flag provided, use GUI selection
-		selectedOrgID, err = utils.SelectOrgForm(apiClient, userID)
+		selectedOrgID, err = utils.SelectOrgForm(cmd.Context(), apiClient, userID)
 		if err != nil {
 			logger.Error("%v", err)
+			utils.LogErrorHint(err)
 			return err
 		}
 	}
@@ -95,16 +121,16 @@ func orgMain(cmd *cobra.Command, opts *OrgCmdOpts) error {
 	}
 
 	// Switch active client if running
-	utils.SwitchActiveClientOrg(selectedOrgID)
+	utils.SwitchActiveClientOrg(cfg, selectedOrgID)
 
 	// Check if olmClient is running and if we need to monitor a switch
-	olmClient := olm.NewClient("")
+	olmClient := olm.NewClient(cfg.SocketPath)
 	if olmClient.IsRunning() {
 		// Get current status - if it doesn't match the new org, monitor the switch
 		currentStatus, err := olmClient.GetStatus()
 		if err == nil && currentStatus != nil && currentStatus.OrgID != selectedOrgID {
 			// Switch was sent, monitor the switch process
-			monitorOrgSwitch(cfg.LogFile, selectedOrgID)
+			monitorOrgSwitch(cfg, selectedOrgID)
 		} else {
 			// Already on the correct org or no status available
 			logger.Success("Successfully selected organization: %s", selectedOrgID)
@@ -117,12 +143,15 @@ func orgMain(cmd *cobra.Command, opts *OrgCmdOpts) error {
 	return nil
 }
 
-// monitorOrgSwitch monitors the organization switch process with log preview
-func monitorOrgSwitch(logFile string, orgID string) {
+// monitorOrgSwitch monitors the organization switch process with log
+// preview, following the events of the client until it is registered in
+// the new organization
+func monitorOrgSwitch(cfg *config.Config, orgID string) {
 	// Show live log preview and status during switch
 	completed, err := tui.NewLogPreview(tui.LogPreviewConfig{
-		LogFile: logFile,
-		Header:  "Switching organization...",
+		LogFile:    cfg.LogFile,
+		SocketPath: cfg.SocketPath,
+		Header:     "Switching organization...",
 		ExitCondition: func(client *olm.Client, status *olm.StatusResponse) (bool, bool) {
 			// Exit when orgId matches new org AND interface is registered again
 			if status != nil && status.OrgID == orgID && status.Registered {
@@ -0,0 +1,228 @@
+package install
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/fosrl/cli/internal/api"
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/daemon"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/olm"
+	"github.com/fosrl/cli/internal/service"
+	"github.com/fosrl/cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+type InstallCmdOpts struct {
+	OrgID    string
+	Profile  string
+	Endpoint string
+	ID       string
+	Secret   string
+}
+
+func InstallCmd() *cobra.Command {
+	opts := InstallCmdOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the client as a systemd service",
+		Long: `Install a systemd unit that runs the client on boot, then enable and start it.
+
+The tunnel options of the profile are written to the unit, so reinstall the
+service after changing the profile. Credentials are written to a file that
+only root can read.`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// `--id` and `--secret` must be specified together
+			if (opts.ID == "") != (opts.Secret == "") {
+				return errors.New("--id and --secret must be provided together")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := installMain(cmd, &opts); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.OrgID, "org", "", "Organization ID (default: organization of the profile or selected organization)")
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "Connection profile `name` with the tunnel options to use")
+	cmd.Flags().StringVar(&opts.Endpoint, "endpoint", "", "Client endpoint (default: host of the active account)")
+	cmd.Flags().StringVar(&opts.ID, "id", "", "Client ID (optional, will use user info if not provided)")
+	cmd.Flags().StringVar(&opts.Secret, "secret", "", "Client secret (optional, will use user info if not provided)")
+
+	_ = cmd.RegisterFlagCompletionFunc("profile", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		cfg := config.ConfigFromContext(cmd.Context())
+		return slices.Sorted(maps.Keys(cfg.Profiles)), cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+func installMain(cmd *cobra.Command, opts *InstallCmdOpts) error {
+	apiClient := api.FromContext(cmd.Context())
+	accountStore := config.AccountStoreFromContext(cmd.Context())
+	cfg := config.ConfigFromContext(cmd.Context())
+
+	if !service.Supported() {
+		err := errors.New("systemd services are only supported on Linux systems running systemd")
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	if os.Geteuid() != 0 {
+		err := errors.New("root permissions are required to install the service")
+		logger.Error("Error: %v", err)
+		logger.Info("Run `sudo pangolin service install`")
+		return err
+	}
+
+	// A client started outside of systemd would conflict with the
+	// one started by the unit
+	if !service.IsActive() && olm.NewClient(cfg.SocketPath).IsRunning() {
+		err := errors.New("a client is already running")
+		logger.Error("Error: %v", err)
+		logger.Info("Stop it with `pangolin down client` before installing the service")
+		return err
+	}
+
+	options := config.Profile{}
+	if opts.Profile != "" {
+		profile, err := cfg.Profile(opts.Profile)
+		if err != nil {
+			logger.Error("Error: %v", err)
+			return err
+		}
+		options = maps.Clone(profile)
+	}
+
+	orgID := opts.OrgID
+	if value, ok := options["org"]; ok && orgID == "" {
+		orgID = config.FormatProfileValue(value)
+	}
+
+	endpoint := opts.Endpoint
+	if value, ok := options["endpoint"]; ok && endpoint == "" {
+		endpoint = config.FormatProfileValue(value)
+	}
+
+	olmID := opts.ID
+	olmSecret := opts.Secret
+	environment := map[string]string{}
+
+	if olmID == "" {
+		activeAccount, err := accountStore.ActiveAccount()
+		if err != nil {
+			logger.Error("Error: %v. Run `pangolin login` to login", err)
+			return err
+		}
+
+		// The secrets of the account may be kept in a credential store
+		// that is not available to root
+		if err := accountStore.SecretsError(); err != nil {
+			logger.Error("Error: %v", err)
+			logger.Info("Pass the client credentials with --id and --secret instead")
+			return err
+		}
+
+		newCredsGenerated, err := utils.EnsureOlmCredentials(cmd.Context(), apiClient, activeAccount)
+		if err != nil {
+			logger.Error("Failed to ensure OLM credentials: %v", err)
+			utils.LogErrorHint(err)
+			return err
+		}
+
+		if newCredsGenerated {
+			if err := accountStore.Save(); err != nil {
+				logger.Error("Failed to save accounts to store: %v", err)
+				return err
+			}
+		}
+
+		olmID = activeAccount.OlmCredentials.ID
+		olmSecret = activeAccount.OlmCredentials.Secret
+
+		if orgID == "" {
+			if activeAccount.OrgID == "" {
+				err := errors.New("organization not selected")
+				logger.Error("Error: %v", err)
+				logger.Info("Run `pangolin select org` to select an organization or pass --org [id] to the command")
+				return err
+			}
+
+			if err := utils.EnsureOrgAccess(cmd.Context(), apiClient, activeAccount); err != nil {
+				logger.Error("%v", err)
+				utils.LogErrorHint(err)
+				return err
+			}
+
+			orgID = activeAccount.OrgID
+		}
+
+		if endpoint == "" {
+			endpoint = activeAccount.Host
+		}
+
+		if activeAccount.SessionToken != "" {
+			environment[daemon.UserTokenEnvVar] = activeAccount.SessionToken
+		}
+	}
+
+	if endpoint == "" {
+		err := errors.New("endpoint is required")
+		logger.Error("Error: %v", err)
+		logger.Info("Please login with a host or provide the --endpoint flag.")
+		return err
+	}
+
+	environment[daemon.OlmIDEnvVar] = olmID
+	environment[daemon.OlmSecretEnvVar] = olmSecret
+
+	tunnelOptions := map[string]string{}
+	for name, value := range options {
+		if name == "org" || name == "endpoint" {
+			continue
+		}
+		tunnelOptions[name] = config.FormatProfileValue(value)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		logger.Error("Error: failed to get executable path: %v", err)
+		return err
+	}
+
+	// The unit does not read the configuration of the installing user
+	args := service.ClientArgs(orgID, endpoint, tunnelOptions)
+	if cfg.SocketPath != olm.GetDefaultSocketPath() {
+		args = append(args, "--socket", cfg.SocketPath)
+	}
+
+	description := "Pangolin client"
+	if opts.Profile != "" {
+		description = fmt.Sprintf("Pangolin client (profile %s)", opts.Profile)
+	}
+
+	unit := service.UnitFile(service.UnitConfig{
+		Description:     description,
+		Executable:      executable,
+		Args:            args,
+		EnvironmentFile: service.EnvironmentFilePath,
+	})
+
+	if err := service.Install(unit, service.EnvironmentFile(environment)); err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	logger.Success("Installed and started %s", service.UnitName)
+	logger.Info("Run `pangolin service status` to check the service")
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package servicecmd
+
+import (
+	"github.com/fosrl/cli/cmd/service/install"
+	"github.com/fosrl/cli/cmd/service/status"
+	"github.com/fosrl/cli/cmd/service/uninstall"
+	"github.com/spf13/cobra"
+)
+
+func ServiceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "service",
+		Short: "Manage the client systemd service",
+		Long:  "Install the client as a systemd service that brings up the tunnel on boot",
+	}
+
+	cmd.AddCommand(install.InstallCmd())
+	cmd.AddCommand(uninstall.UninstallCmd())
+	cmd.AddCommand(status.StatusCmd())
+
+	return cmd
+}
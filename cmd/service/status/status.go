@@ -0,0 +1,54 @@
+package status
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/service"
+	"github.com/fosrl/cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func StatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the client systemd service status",
+		Long:  "Show whether the client systemd service is installed, enabled and running",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := statusMain(); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func statusMain() error {
+	if !service.Supported() {
+		logger.Info("systemd is not available on this system")
+		return nil
+	}
+
+	status := service.GetStatus()
+
+	headers := []string{"UNIT", "INSTALLED", "ENABLED", "STATE"}
+	rows := [][]string{
+		{
+			service.UnitName,
+			fmt.Sprintf("%t", status.Installed),
+			fmt.Sprintf("%t", status.Enabled),
+			status.ActiveState,
+		},
+	}
+	utils.PrintTable(headers, rows)
+
+	if status.Installed {
+		fmt.Println("")
+		logger.Info("Run `journalctl -u %s` to view the client logs", service.UnitName)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package uninstall
+
+import (
+	"errors"
+	"os"
+
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+func UninstallCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Uninstall the client systemd service",
+		Long:  "Stop and disable the client systemd service, and remove its unit and credentials",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := uninstallMain(); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func uninstallMain() error {
+	if os.Geteuid() != 0 {
+		err := errors.New("root permissions are required to uninstall the service")
+		logger.Error("Error: %v", err)
+		logger.Info("Run `sudo pangolin service uninstall`")
+		return err
+	}
+
+	if !service.GetStatus().Installed {
+		err := errors.New("the service is not installed")
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	if err := service.Uninstall(); err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	logger.Success("Uninstalled %s", service.UnitName)
+
+	return nil
+}
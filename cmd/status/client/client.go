@@ -1,19 +1,28 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/fosrl/cli/internal/config"
 	"github.com/fosrl/cli/internal/logger"
 	"github.com/fosrl/cli/internal/olm"
+	"github.com/fosrl/cli/internal/tui"
 	"github.com/fosrl/cli/internal/utils"
 	"github.com/spf13/cobra"
 )
 
 type ClientStatusCmdOpts = struct {
-	JSON bool
+	JSON  bool
+	Name  string
+	All   bool
+	Watch bool
 }
 
 func ClientStatusCmd() *cobra.Command {
@@ -23,21 +32,48 @@ func ClientStatusCmd() *cobra.Command {
 		Use:   "client",
 		Short: "Show client status",
 		Long:  "Display current client connection status and peer information",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if opts.All && opts.Name != "" {
+				return errors.New("--name and --all options conflict")
+			}
+			if opts.All && opts.Watch {
+				return errors.New("--watch and --all options conflict")
+			}
+			return nil
+		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := clientStatusMain(&opts); err != nil {
+			if err := clientStatusMain(cmd, &opts); err != nil {
 				os.Exit(1)
 			}
 		},
 	}
 
 	cmd.Flags().BoolVar(&opts.JSON, "json", false, "Print raw JSON response")
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Name of the client instance to show (default: the default instance)")
+	cmd.Flags().BoolVar(&opts.All, "all", false, "List every running client instance")
+	cmd.Flags().BoolVarP(&opts.Watch, "watch", "w", false, "Show a live dashboard of the client peers, or print client events as they happen, one JSON object per line, with --json")
 
 	return cmd
 }
 
-func clientStatusMain(opts *ClientStatusCmdOpts) error {
-	// Get socket path from config or use default
-	client := olm.NewClient("")
+func clientStatusMain(cmd *cobra.Command, opts *ClientStatusCmdOpts) error {
+	cfg := config.ConfigFromContext(cmd.Context())
+
+	if opts.All {
+		return allInstancesStatus(cfg, opts)
+	}
+
+	instance, err := cfg.Instance(opts.Name)
+	if err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	client := olm.NewClient(instance.SocketPath)
+	if err := client.CheckSocket(); err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
 
 	// Check if client is running
 	if !client.IsRunning() {
@@ -45,6 +81,23 @@ func clientStatusMain(opts *ClientStatusCmdOpts) error {
 		return nil
 	}
 
+	if opts.Watch {
+		// The dashboard needs a terminal, so events are printed as lines
+		// when the output is redirected
+		if !opts.JSON && isTerminal(os.Stdout) {
+			if err := tui.NewDashboard(tui.DashboardConfig{
+				SocketPath: instance.SocketPath,
+				LogFile:    instance.LogFile,
+				Header:     "Client " + instance.DisplayName(),
+			}); err != nil {
+				logger.Error("Error: %v", err)
+				return err
+			}
+			return nil
+		}
+		return watchEvents(cmd.Context(), client, opts.JSON)
+	}
+
 	// Get status
 	status, err := client.GetStatus()
 	if err != nil {
@@ -57,13 +110,174 @@ func clientStatusMain(opts *ClientStatusCmdOpts) error {
 		return printJSON(status)
 	} else {
 		printStatusTable(status)
+		checkProject(status)
+	}
+
+	return nil
+}
+
+// watchEvents prints the events of the client until it goes away or the
+// command is interrupted
+func watchEvents(ctx context.Context, client *olm.Client, asJSON bool) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	events, err := client.Subscribe(ctx)
+	if err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	for event := range events {
+		if asJSON {
+			if err := encoder.Encode(event); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fmt.Println(formatEvent(event))
+	}
+
+	return nil
+}
+
+// isTerminal reports whether the file is a terminal
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// formatEvent formats an event as a line of text
+func formatEvent(event olm.Event) string {
+	line := fmt.Sprintf("%s  %-17s", event.Time.Format(time.TimeOnly), event.Type)
+
+	switch {
+	case event.Peer != nil:
+		line += " site " + event.Peer.SiteName
+		if event.Peer.Endpoint != "" {
+			line += " (" + event.Peer.Endpoint + ")"
+		}
+	case event.OrgID != "":
+		line += " org " + event.OrgID
+	}
+
+	if event.Message != "" {
+		line += ": " + event.Message
 	}
 
+	return line
+}
+
+// instanceStatus is the status of a client instance as printed by --all
+// in JSON mode
+type instanceStatus struct {
+	Name       string              `json:"name"`
+	SocketPath string              `json:"socketPath"`
+	Status     *olm.StatusResponse `json:"status"`
+
+	displayName string
+}
+
+// allInstancesStatus prints the status of every running client instance
+func allInstancesStatus(cfg *config.Config, opts *ClientStatusCmdOpts) error {
+	instances, err := cfg.Instances()
+	if err != nil {
+		logger.Error("Error: failed to list instances: %v", err)
+		return err
+	}
+
+	statuses := []instanceStatus{}
+	for _, instance := range instances {
+		client := olm.NewClient(instance.SocketPath)
+		if err := client.CheckSocket(); err != nil {
+			logger.Warning("Skipping instance %s: %v", instance.DisplayName(), err)
+			continue
+		}
+		if !client.IsRunning() {
+			continue
+		}
+
+		status, err := client.GetStatus()
+		if err != nil {
+			logger.Warning("Failed to get status of instance %s: %v", instance.DisplayName(), err)
+			continue
+		}
+
+		statuses = append(statuses, instanceStatus{
+			Name:       instance.Name,
+			SocketPath: instance.SocketPath,
+			Status:     status,
+
+			displayName: instance.DisplayName(),
+		})
+	}
+
+	if opts.JSON {
+		return printJSON(statuses)
+	}
+
+	if len(statuses) == 0 {
+		logger.Info("No client is currently running")
+		return nil
+	}
+
+	headers := []string{"NAME", "STATUS", "ORG", "PEERS", "SOCKET"}
+	rows := [][]string{}
+	for _, s := range statuses {
+		connected := 0
+		for _, peer := range s.Status.PeerStatuses {
+			if peer.Connected {
+				connected++
+			}
+		}
+
+		rows = append(rows, []string{
+			s.displayName,
+			formatStatus(s.Status.Connected),
+			s.Status.OrgID,
+			fmt.Sprintf("%d/%d", connected, len(s.Status.PeerStatuses)),
+			s.SocketPath,
+		})
+	}
+	utils.PrintTable(headers, rows)
+
 	return nil
 }
 
+// checkProject warns when the running client does not provide the
+// connection declared by the project file of the current directory
+func checkProject(status *olm.StatusResponse) {
+	project, err := config.FindProjectConfig(".")
+	if err != nil {
+		logger.Warning("Failed to load project file: %v", err)
+		return
+	}
+	if project == nil {
+		return
+	}
+
+	if project.Org != "" && status.OrgID != project.Org {
+		fmt.Println("")
+		logger.Warning("Connected to organization %s, but project %s requires %s", status.OrgID, project.Path(), project.Org)
+		logger.Info("Run `pangolin select org --org %s` to switch organizations", project.Org)
+	}
+
+	connected := map[string]bool{}
+	for _, peer := range status.PeerStatuses {
+		connected[peer.SiteName] = connected[peer.SiteName] || peer.Connected
+	}
+
+	for _, resource := range project.Resources {
+		if !connected[resource] {
+			logger.Warning("Site %s required by project %s is not connected", resource, project.Path())
+		}
+	}
+}
+
 // printJSON prints the status response as JSON
-func printJSON(status *olm.StatusResponse) error {
+func printJSON(status any) error {
 	jsonData, err := json.MarshalIndent(status, "", "  ")
 	if err != nil {
 		logger.Error("Error marshaling JSON: %v", err)
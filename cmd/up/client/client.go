@@ -5,19 +5,25 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/fosrl/cli/internal/api"
 	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/daemon"
+	"github.com/fosrl/cli/internal/hooks"
 	"github.com/fosrl/cli/internal/logger"
 	"github.com/fosrl/cli/internal/olm"
+	"github.com/fosrl/cli/internal/service"
 	"github.com/fosrl/cli/internal/tui"
 	"github.com/fosrl/cli/internal/utils"
 	versionpkg "github.com/fosrl/cli/internal/version"
@@ -27,10 +33,13 @@ import (
 )
 
 const (
-	defaultDNSServer  = "8.8.8.8"
-	defaultEnableAPI  = true
-	defaultSocketPath = "/var/run/olm.sock"
-	defaultAgent      = "Pangolin CLI"
+	defaultDNSServer = "8.8.8.8"
+	defaultEnableAPI = true
+	defaultAgent     = "Pangolin CLI"
+
+	// daemonStartTimeout is how long to wait for the daemon started
+	// in the background to accept requests
+	daemonStartTimeout = 10 * time.Second
 )
 
 type ClientUpCmdOpts struct {
@@ -49,6 +58,16 @@ type ClientUpCmdOpts struct {
 	TlsClientCert string
 	Attached      bool
 	Silent        bool
+	Profile       string
+	NoProject     bool
+	NoHooks       bool
+	Name          string
+	Restart       string
+	MaxRestarts   int
+	RestartWindow time.Duration
+	Wait          bool
+	WaitTimeout   time.Duration
+	WaitSites     []string
 	OverrideDNS   bool
 	TunnelDNS     bool
 	UpstreamDNS   []string
@@ -71,11 +90,29 @@ func ClientUpCmd() *cobra.Command {
 				return errors.New("--silent and --attached options conflict")
 			}
 
+			if opts.Attached && opts.Wait {
+				return errors.New("--wait and --attached options conflict")
+			}
+
+			if !opts.Wait && (cmd.Flags().Changed("wait-timeout") || cmd.Flags().Changed("wait-sites")) {
+				return errors.New("--wait-timeout and --wait-sites require --wait")
+			}
+
+			switch opts.Restart {
+			case restartNo:
+			case restartOnFailure:
+				if !opts.Attached {
+					return errors.New("--restart requires --attach")
+				}
+			default:
+				return fmt.Errorf("invalid --restart policy %q, must be %s or %s", opts.Restart, restartNo, restartOnFailure)
+			}
+
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := clientUpMain(cmd, &opts, args); err != nil {
-				os.Exit(1)
+			if err := clientUpMain(cmd, &opts); err != nil {
+				os.Exit(utils.ExitCode(err))
 			}
 		},
 	}
@@ -89,7 +126,7 @@ func ClientUpCmd() *cobra.Command {
 	cmd.Flags().StringVar(&opts.Endpoint, "endpoint", "", "Client endpoint (required if not logged in)")
 	cmd.Flags().IntVar(&opts.MTU, "mtu", 1280, "Maximum transmission unit")
 	cmd.Flags().StringVar(&opts.DNS, "netstack-dns", defaultDNSServer, "DNS `server` to use for Netstack")
-	cmd.Flags().StringVar(&opts.InterfaceName, "interface-name", "pangolin", "Interface `name`")
+	cmd.Flags().StringVar(&opts.InterfaceName, "interface-name", olm.DefaultInterfaceName, "Interface `name`")
 	cmd.Flags().StringVar(&opts.LogLevel, "log-level", "info", "Log level")
 	cmd.Flags().StringVar(&opts.HTTPAddr, "http-addr", "", "HTTP address for API server")
 	cmd.Flags().DurationVar(&opts.PingInterval, "ping-interval", 5*time.Second, "Ping `interval`")
@@ -101,11 +138,28 @@ func ClientUpCmd() *cobra.Command {
 	cmd.Flags().StringSliceVar(&opts.UpstreamDNS, "upstream-dns", []string{defaultDNSServer}, "List of DNS servers to use for external DNS resolution if overriding system DNS")
 	cmd.Flags().BoolVar(&opts.Attached, "attach", false, "Run in attached (foreground) mode, (default: detached (background) mode)")
 	cmd.Flags().BoolVar(&opts.Silent, "silent", false, "Disable TUI and run silently when detached")
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "Connection profile `name` to use (explicit flags take precedence)")
+
+	cmd.Flags().StringVar(&opts.Restart, "restart", restartNo, "Restart `policy` in attached mode: "+restartNo+" or "+restartOnFailure)
+	cmd.Flags().IntVar(&opts.MaxRestarts, "max-restarts", 5, "Maximum number of restarts within the restart window before giving up")
+	cmd.Flags().DurationVar(&opts.RestartWindow, "restart-window", 10*time.Minute, "Time `window` in which restarts are counted")
+	cmd.Flags().BoolVar(&opts.Wait, "wait", false, "Wait until the client is registered instead of showing the TUI, exiting with a distinct status on failure")
+	cmd.Flags().DurationVar(&opts.WaitTimeout, "wait-timeout", time.Minute, "Maximum `duration` to wait for the client")
+	cmd.Flags().StringSliceVar(&opts.WaitSites, "wait-sites", nil, "Site names or IDs that must be connected before --wait returns (default: resources of the project file)")
+	cmd.Flags().BoolVar(&opts.NoProject, "no-project", false, "Ignore the "+config.ProjectFileName+" file of the current project")
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Name of the client `instance`, to run several clients side by side (default: the default instance)")
+	cmd.Flags().BoolVar(&opts.NoHooks, "no-hooks", false, "Do not run the connection hooks")
+
+	_ = cmd.RegisterFlagCompletionFunc("restart", cobra.FixedCompletions([]string{restartNo, restartOnFailure}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("profile", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		cfg := config.ConfigFromContext(cmd.Context())
+		return slices.Sorted(maps.Keys(cfg.Profiles)), cobra.ShellCompDirectiveNoFileComp
+	})
 
 	return cmd
 }
 
-func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string) error {
+func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts) error {
 	apiClient := api.FromContext(cmd.Context())
 	accountStore := config.AccountStoreFromContext(cmd.Context())
 	cfg := config.ConfigFromContext(cmd.Context())
@@ -116,10 +170,57 @@ func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string)
 		return err
 	}
 
+	instance, err := cfg.Instance(opts.Name)
+	if err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
+
+	// The systemd unit starts the default instance itself, any other
+	// client of that instance would conflict with it
+	if instance.Name == "" && os.Getenv(service.ServiceEnvVar) != "1" && service.IsActive() {
+		err := fmt.Errorf("the client is managed by the %s systemd unit", service.UnitName)
+		logger.Error("Error: %v", err)
+		logger.Info("Run `sudo systemctl restart %s` to restart it, or `sudo pangolin service uninstall` to remove it", service.UnitName)
+		return err
+	}
+
+	if opts.Profile != "" {
+		if err := applyProfile(cmd, cfg, opts.Profile); err != nil {
+			logger.Error("Error: %v", err)
+			return err
+		}
+	}
+
+	// The project file is applied after the profile, so that both
+	// explicit flags and an explicitly selected profile take precedence
+	var project *config.ProjectConfig
+	if !opts.NoProject {
+		project, err = applyProjectConfig(cmd)
+		if err != nil {
+			logger.Error("Error: %v", err)
+			return err
+		}
+	}
+
+	// A named instance gets an interface of its own, unless one was
+	// given. Setting the flag also passes it on to the daemon.
+	if instance.Name != "" && !cmd.Flags().Changed("interface-name") {
+		_ = cmd.Flags().Set("interface-name", instance.InterfaceName)
+	}
+
 	// Check if a client is already running
-	olmClient := olm.NewClient("")
+	olmClient := olm.NewClient(instance.SocketPath)
+	if err := olmClient.CheckSocket(); err != nil {
+		logger.Error("Error: %v", err)
+		return err
+	}
 	if olmClient.IsRunning() {
 		err := errors.New("a client is already running")
+		if instance.Name != "" {
+			err = fmt.Errorf("a client is already running for instance %s", instance.Name)
+		}
+		err = utils.NewExitError(utils.ExitCodeAlreadyRunning, err)
 		logger.Error("Error: %v", err)
 		return err
 	}
@@ -130,8 +231,23 @@ func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string)
 	olmID := opts.ID
 	olmSecret := opts.Secret
 
+	// The daemon hands over credentials through the environment, so
+	// that they do not show up in the process list
+	if olmID == "" && olmSecret == "" {
+		olmID = os.Getenv(daemon.OlmIDEnvVar)
+		olmSecret = os.Getenv(daemon.OlmSecretEnvVar)
+	}
+
 	credentialsFromKeyring := olmID == "" && olmSecret == ""
 
+	// API key accounts have no client credentials of their own, but
+	// still provide the organization and host when the credentials
+	// are passed directly, as in headless runs with PANGOLIN_API_KEY
+	accountDefaults := credentialsFromKeyring
+	if activeAccount, err := accountStore.ActiveAccount(); err == nil && activeAccount.IsAPIKey() {
+		accountDefaults = true
+	}
+
 	if credentialsFromKeyring {
 		activeAccount, err := accountStore.ActiveAccount()
 		if err != nil {
@@ -139,10 +255,19 @@ func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string)
 			return err
 		}
 
+		if project != nil && project.Host != "" &&
+			utils.FormatHostnameBaseURL(project.Host) != utils.FormatHostnameBaseURL(activeAccount.Host) {
+			err := fmt.Errorf("project %s requires host %s, but the active account is on %s", project.Path(), project.Host, activeAccount.Host)
+			logger.Error("Error: %v", err)
+			logger.Info("Run `pangolin login %s` or `pangolin select account --host %s`, or pass --no-project to ignore the project file", project.Host, project.Host)
+			return err
+		}
+
 		// Ensure OLM credentials exist and are valid
-		newCredsGenerated, err := utils.EnsureOlmCredentials(apiClient, activeAccount)
+		newCredsGenerated, err := utils.EnsureOlmCredentials(cmd.Context(), apiClient, activeAccount)
 		if err != nil {
 			logger.Error("Failed to ensure OLM credentials: %v", err)
+			utils.LogErrorHint(err)
 			return err
 		}
 
@@ -162,18 +287,23 @@ func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string)
 
 	// If no organization ID is specified, then use the active user's
 	// selected organization if possible.
-	if orgID == "" && credentialsFromKeyring {
+	if orgID == "" && accountDefaults {
 		activeAccount, _ := accountStore.ActiveAccount()
 
 		if activeAccount.OrgID == "" {
 			err := errors.New("organization not selected")
 			logger.Error("Error: %v", err)
-			logger.Info("Run `pangolin select org` to select an organization or pass --org [id] to the command")
+			if activeAccount.IsAPIKey() {
+				logger.Info("Pass --org [id] to the command or set %s", config.OrgEnvVar)
+			} else {
+				logger.Info("Run `pangolin select org` to select an organization or pass --org [id] to the command")
+			}
 			return err
 		}
 
-		if err := utils.EnsureOrgAccess(apiClient, activeAccount); err != nil {
+		if err := utils.EnsureOrgAccess(cmd.Context(), apiClient, activeAccount); err != nil {
 			logger.Error("%v", err)
+			utils.LogErrorHint(err)
 			return err
 		}
 
@@ -183,12 +313,12 @@ func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string)
 	// Handle log file setup - if detached mode, always use log file
 	var logFile string
 	if !opts.Attached {
-		logFile = cfg.LogFile
+		logFile = instance.LogFile
 	}
 
 	var endpoint string
 
-	if opts.Endpoint == "" && credentialsFromKeyring {
+	if opts.Endpoint == "" && accountDefaults {
 		activeAccount, _ := accountStore.ActiveAccount()
 		endpoint = activeAccount.Host
 	} else {
@@ -202,132 +332,90 @@ func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string)
 		return err
 	}
 
-	// Handle detached mode - subprocess self without --attach flag
-	// Skip detached mode if already running as root (we're a subprocess spawned by sudo)
+	// Handle detached mode - the daemon runs the client as root.
+	// Skip detached mode if already running as root, which is also
+	// how the daemon starts the client, unless the client must be
+	// waited for in the background.
 	isRunningAsRoot := runtime.GOOS != "windows" && os.Geteuid() == 0
-	if !opts.Attached && !isRunningAsRoot {
-		executable, err := os.Executable()
+	if !opts.Attached && (!isRunningAsRoot || opts.Wait) {
+		request := daemon.StartRequest{
+			Name:       instance.Name,
+			ID:         olmID,
+			Secret:     olmSecret,
+			Endpoint:   endpoint,
+			OrgID:      orgID,
+			LogFile:    logFile,
+			SocketPath: instance.SocketPath,
+			Options:    tunnelOptions(cmd),
+		}
+
+		// The client runs as root and cannot read the user's
+		// credential store, so the session token is handed over too
+		if credentialsFromKeyring {
+			if activeAccount, err := accountStore.ActiveAccount(); err == nil {
+				request.UserToken = activeAccount.SessionToken
+			}
+		}
+
+		daemonClient, err := ensureDaemon()
 		if err != nil {
-			logger.Error("Error: failed to get executable path: %v", err)
+			logger.Error("Error: %v", err)
 			return err
 		}
 
-		// Build command arguments, excluding --attach flag
-		cmdArgs := []string{"up", "client"}
-
-		// Add org flag if needed (required for subprocess, which runs as
-		// root and won't have user's config)
-		if orgID != "" {
-			cmdArgs = append(cmdArgs, "--org", orgID)
+		// The client started by the daemon does not run hooks, they
+		// are run here as the user instead
+		conn := hooks.Connection{
+			Instance:      instance.Name,
+			OrgID:         orgID,
+			InterfaceName: opts.InterfaceName,
+			Endpoint:      endpoint,
 		}
-
-		// Add all flags that were set (except --attach)
-		// OLM credentials are always included (from flags, config, or newly created)
-		cmdArgs = append(cmdArgs, "--id", olmID)
-		cmdArgs = append(cmdArgs, "--secret", olmSecret)
-
-		// Always pass endpoint to subprocess (required, subprocess won't have user's config)
-		// Get endpoint from flag or hostname config (same logic as attached mode)
-		cmdArgs = append(cmdArgs, "--endpoint", endpoint)
-
-		// Optional flags - only include if they were explicitly set
-		if cmd.Flags().Changed("mtu") {
-			cmdArgs = append(cmdArgs, "--mtu", fmt.Sprintf("%d", opts.MTU))
+		if !opts.NoHooks {
+			hooks.Run(cfg.Hooks, hooks.PreUp, conn)
 		}
-		if cmd.Flags().Changed("netstack-dns") {
-			cmdArgs = append(cmdArgs, "--netstack-dns", opts.DNS)
-		}
-		if cmd.Flags().Changed("interface-name") {
-			cmdArgs = append(cmdArgs, "--interface-name", opts.InterfaceName)
-		}
-		if cmd.Flags().Changed("log-level") {
-			cmdArgs = append(cmdArgs, "--log-level", opts.LogLevel)
-		}
-		if cmd.Flags().Changed("http-addr") {
-			cmdArgs = append(cmdArgs, "--http-addr", opts.HTTPAddr)
-		}
-		if cmd.Flags().Changed("ping-interval") {
-			cmdArgs = append(cmdArgs, "--ping-interval", opts.PingInterval.String())
-		}
-		if cmd.Flags().Changed("ping-timeout") {
-			cmdArgs = append(cmdArgs, "--ping-timeout", opts.PingTimeout.String())
-		}
-		if cmd.Flags().Changed("holepunch") {
-			if opts.Holepunch {
-				cmdArgs = append(cmdArgs, "--holepunch")
-			} else {
-				cmdArgs = append(cmdArgs, "--holepunch=false")
-			}
-		}
-		if cmd.Flags().Changed("tls-client-cert") {
-			cmdArgs = append(cmdArgs, "--tls-client-cert", opts.TlsClientCert)
-		}
-		if cmd.Flags().Changed("override-dns") {
-			if opts.OverrideDNS {
-				cmdArgs = append(cmdArgs, "--override-dns")
-			} else {
-				cmdArgs = append(cmdArgs, "--override-dns=false")
-			}
-		}
-		if cmd.Flags().Changed("tunnel-dns") {
-			if opts.TunnelDNS {
-				cmdArgs = append(cmdArgs, "--tunnel-dns")
-			} else {
-				cmdArgs = append(cmdArgs, "--tunnel-dns=false")
+
+		if err := daemonClient.Start(request); err != nil {
+			if errors.Is(err, daemon.ErrAlreadyRunning) {
+				err = utils.NewExitError(utils.ExitCodeAlreadyRunning, err)
 			}
+			logger.Error("Error: failed to start client: %v", err)
+			return err
 		}
-		if cmd.Flags().Changed("upstream-dns") {
-			// Comma sep
-			cmdArgs = append(cmdArgs, "--upstream-dns", strings.Join(opts.UpstreamDNS, ","))
-		}
-
-		// Add positional args if any
-		cmdArgs = append(cmdArgs, extraArgs...)
-
-		// Create command - subprocess should run with elevated permissions
-		var procCmd *exec.Cmd
-		if runtime.GOOS != "windows" {
-			// Use sudo with a shell wrapper to background the subprocess
-			// This allows sudo to exit immediately after starting the subprocess
-			// The subprocess needs root access for network interface creation
-			// Build shell command with proper quoting using printf %q
-			var shellArgs []string
-			shellArgs = append(shellArgs, executable)
-			shellArgs = append(shellArgs, cmdArgs...)
-			// Export environment variable to indicate credentials came from config
-			// This allows subprocess to distinguish between user-provided credentials and stored credentials
-			shellCmd := ""
-			if credentialsFromKeyring {
-				shellCmd = "export PANGOLIN_CREDENTIALS_FROM_KEYRING=1 && "
+
+		// Without the TUI, post_up hooks need the client to be
+		// registered before they can run
+		runPostUp := !opts.NoHooks && len(hooks.Commands(cfg.Hooks, hooks.PostUp, orgID)) > 0
+		if opts.Wait || (opts.Silent && runPostUp) {
+			// Sites required by the project are waited for unless
+			// other sites were given
+			var sites []string
+			if opts.Wait {
+				sites = opts.WaitSites
+				if !cmd.Flags().Changed("wait-sites") && project != nil {
+					sites = project.Resources
+				}
 			}
-			// Build command: nohup executable args >/dev/null 2>&1 &
-			shellCmd += "nohup"
-			for _, arg := range shellArgs {
-				shellCmd += " " + fmt.Sprintf("%q", arg)
+
+			if err := waitForClient(cmd.Context(), olmClient, daemonClient, instance.Name, opts.WaitTimeout, sites, opts.Silent); err != nil {
+				if !opts.Wait {
+					logger.Warning("Skipping post_up hooks: %v", err)
+					return nil
+				}
+
+				logger.Error("Error: %v", err)
+				logger.Info("Run `pangolin status client` and `pangolin logs client` to find out why")
+				return err
 			}
-			shellCmd += " >/dev/null 2>&1 &"
-			procCmd = exec.Command("sudo", "sh", "-c", shellCmd)
-			// Connect stdin/stderr so sudo can prompt for password interactively
-			procCmd.Stdin = os.Stdin
-			procCmd.Stdout = nil
-			procCmd.Stderr = os.Stderr
-		} else {
-			err := errors.New("detached mode is not supported on Windows")
-			logger.Error("Error: %v", err)
-			return err
-		}
 
-		// Start the process
-		if err := procCmd.Start(); err != nil {
-			logger.Error("Error: failed to start detached process: %v", err)
-			return err
-		}
+			if runPostUp {
+				runPostUpHooks(cfg.Hooks, olmClient, conn)
+			}
 
-		// Wait for sudo to complete (password prompt + subprocess start)
-		// The shell wrapper backgrounds the subprocess, so sudo exits immediately
-		if err := procCmd.Wait(); err != nil {
-			logger.Error("Error: failed to start subprocess: %v", err)
-			return err
+			if !opts.Silent {
+				logger.Success("Client is ready")
+			}
+			return nil
 		}
 
 		// In silent mode, skip TUI and just exit after starting the process
@@ -337,8 +425,9 @@ func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string)
 
 		// Show live log preview and status
 		completed, err := tui.NewLogPreview(tui.LogPreviewConfig{
-			LogFile: logFile,
-			Header:  "Starting up client...",
+			LogFile:    logFile,
+			SocketPath: instance.SocketPath,
+			Header:     "Starting up client...",
 			ExitCondition: func(client *olm.Client, status *olm.StatusResponse) (bool, bool) {
 				// Exit when interface is registered
 				if status != nil && status.Registered {
@@ -347,10 +436,8 @@ func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string)
 				return false, false
 			},
 			OnEarlyExit: func(client *olm.Client) {
-				// Kill the subprocess if user exits early
-				if client.IsRunning() {
-					_, _ = client.Exit()
-				}
+				// Stop the client if user exits early
+				_ = daemonClient.Stop(instance.Name)
 			},
 			StatusFormatter: func(isRunning bool, status *olm.StatusResponse) string {
 				if !isRunning || status == nil {
@@ -373,10 +460,25 @@ func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string)
 		} else {
 			// Completed successfully
 			logger.Success("Client interface created successfully")
+
+			if runPostUp {
+				runPostUpHooks(cfg.Hooks, olmClient, conn)
+			}
 		}
 		return nil
 	}
 
+	// Check if running with elevated permissions (required for network interface creation)
+	// This check is only for attached mode; in detached mode, the subprocess runs elevated
+	if runtime.GOOS != "windows" {
+		if os.Geteuid() != 0 {
+			err := errors.New("elevated permissions are required for network interface creation")
+			logger.Error("Error: %v", err)
+			logger.Info("Please run with sudo or use detached mode (default) to run the subprocess elevated.")
+			return err
+		}
+	}
+
 	enableAPI := defaultEnableAPI
 
 	// In detached mode, API cannot be disabled (required for status/control)
@@ -384,7 +486,7 @@ func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string)
 		enableAPI = true
 	}
 
-	socketPath := defaultSocketPath
+	socketPath := instance.SocketPath
 
 	upstreamDNS := make([]string, 0, len(opts.UpstreamDNS))
 	for _, server := range opts.UpstreamDNS {
@@ -408,20 +510,17 @@ func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string)
 
 	// Setup log file if specified
 	if logFile != "" {
-		if err := setupLogFile(cfg.LogFile); err != nil {
+		if err := setupLogFile(logFile); err != nil {
 			logger.Error("Error: failed to setup log file: %v", err)
 			return err
 		}
 	}
 
-	// Get UserToken from config if credentials came from config
-	// Check environment variable to distinguish between:
-	// - Parent process passing id/secret from config (should fetch userToken)
-	// - User directly passing id/secret (should NOT fetch userToken)
-	var userToken string
-	credentialsFromKeyringEnv := os.Getenv("PANGOLIN_CREDENTIALS_FROM_KEYRING")
-	if credentialsFromKeyringEnv == "1" || credentialsFromKeyring {
-		// Credentials came from config, fetch userToken from secrets
+	// The daemon hands over the session token of the account the
+	// credentials came from. When running attached with stored
+	// credentials, the token is read from the account instead.
+	userToken := os.Getenv(daemon.UserTokenEnvVar)
+	if userToken == "" && credentialsFromKeyring {
 		activeAccount, err := accountStore.ActiveAccount()
 		if err != nil {
 			logger.Error("Failed to get session token: %v", err)
@@ -431,11 +530,81 @@ func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string)
 		userToken = activeAccount.SessionToken
 	}
 
+	if opts.Restart == restartOnFailure {
+		return superviseClient(cmd, supervisedClient{
+			OlmID:                  olmID,
+			OlmSecret:              olmSecret,
+			UserToken:              userToken,
+			Endpoint:               endpoint,
+			OrgID:                  orgID,
+			CredentialsFromKeyring: credentialsFromKeyring,
+			MaxRestarts:            opts.MaxRestarts,
+			RestartWindow:          opts.RestartWindow,
+		})
+	}
+
+	conn := hooks.Connection{
+		Instance:      instance.Name,
+		OrgID:         orgID,
+		InterfaceName: opts.InterfaceName,
+		Endpoint:      endpoint,
+	}
+	// The post_down hooks run once, either when the tunnel was closed or
+	// before one of the callbacks below exits the process, which skips
+	// deferred calls
+	var postDownOnce sync.Once
+	runPostDown := func() {
+		if !opts.NoHooks {
+			postDownOnce.Do(func() {
+				hooks.Run(cfg.Hooks, hooks.PostDown, conn)
+			})
+		}
+	}
+
+	if !opts.NoHooks {
+		hooks.Run(cfg.Hooks, hooks.PreUp, conn)
+	}
+
+	// Deferred before closing the tunnel, so that it runs after
+	defer runPostDown()
+
 	// Create context for signal handling and cleanup
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer olmpkg.Close()
 	defer stop()
 
+	// Publish the events of the client for subscribers such as
+	// `status client --watch`. The last events are published before
+	// the process exits, which skips deferred calls.
+	var publisher *olm.Publisher
+	if enableAPI {
+		var err error
+		publisher, err = olm.NewPublisher(socketPath)
+		if err != nil {
+			logger.Warning("Failed to publish client events: %v", err)
+		} else {
+			go publisher.Watch(ctx, olmClient)
+		}
+	}
+	closeEvents := func(events ...olm.Event) {
+		if publisher == nil {
+			return
+		}
+		for _, event := range events {
+			publisher.Publish(event)
+		}
+		publisher.Close()
+	}
+	defer closeEvents(olm.Event{Type: olm.EventTerminating})
+
+	if !opts.NoHooks && len(hooks.Commands(cfg.Hooks, hooks.PostUp, orgID)) > 0 {
+		go func() {
+			if waitForRegistration(ctx, olmClient) {
+				runPostUpHooks(cfg.Hooks, olmClient, conn)
+			}
+		}()
+	}
+
 	// Create OLM GlobalConfig with hardcoded values from Swift
 	olmInitConfig := olmpkg.GlobalConfig{
 		LogLevel:   opts.LogLevel,
@@ -446,16 +615,25 @@ func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string)
 		Agent:      defaultAgent,
 		OnTerminated: func() {
 			logger.Info("Client process terminated")
+			closeEvents(olm.Event{Type: olm.EventTerminating, Message: "terminated by the server"})
 			stop()
-			os.Exit(0)
+			runPostDown()
+			os.Exit(utils.ExitCodeTerminated)
 		},
 		OnAuthError: func(statusCode int, message string) {
 			logger.Error("Authentication error: %d %s", statusCode, message)
+			closeEvents(
+				olm.Event{Type: olm.EventAuthError, Message: fmt.Sprintf("%d %s", statusCode, message)},
+				olm.Event{Type: olm.EventTerminating},
+			)
 			stop()
-			os.Exit(1)
+			runPostDown()
+			os.Exit(utils.ExitCodeAuthError)
 		},
 		OnExit: func() {
 			logger.Info("Client process exiting")
+			closeEvents(olm.Event{Type: olm.EventTerminating})
+			runPostDown()
 			os.Exit(0)
 		},
 	}
@@ -482,17 +660,6 @@ func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string)
 		olmConfig.UserToken = userToken
 	}
 
-	// Check if running with elevated permissions (required for network interface creation)
-	// This check is only for attached mode; in detached mode, the subprocess runs elevated
-	if runtime.GOOS != "windows" {
-		if os.Geteuid() != 0 {
-			err := errors.New("elevated permissions are required for network interface creation")
-			logger.Error("Error: %v", err)
-			logger.Info("Please run with sudo or use detached mode (default) to run the subprocess elevated.")
-			return err
-		}
-	}
-
 	olmpkg.Init(ctx, olmInitConfig)
 	if enableAPI {
 		_ = olmpkg.StartApi()
@@ -502,6 +669,184 @@ func clientUpMain(cmd *cobra.Command, opts *ClientUpCmdOpts, extraArgs []string)
 	return nil
 }
 
+// runPostUpHooks runs the post_up hooks with the peers of the registered
+// client
+func runPostUpHooks(hooksConfig config.Hooks, olmClient *olm.Client, conn hooks.Connection) {
+	if status, err := olmClient.GetStatus(); err == nil {
+		conn.Peers = status.PeerStatuses
+	}
+	hooks.Run(hooksConfig, hooks.PostUp, conn)
+}
+
+// waitForRegistration polls the client until it is registered. It
+// returns false if the context was canceled first.
+func waitForRegistration(ctx context.Context, olmClient *olm.Client) bool {
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if status, err := olmClient.GetStatus(); err == nil && status.Registered {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// tunnelOptions returns the tunnel options that were set, keyed by
+// profile key name, to be passed on to the daemon. The organization and
+// endpoint are passed separately.
+func tunnelOptions(cmd *cobra.Command) map[string]string {
+	options := map[string]string{}
+	for _, key := range config.ProfileKeys() {
+		if key.Name == "org" || key.Name == "endpoint" || !cmd.Flags().Changed(key.FlagName()) {
+			continue
+		}
+
+		if key.Type == config.ProfileKeyTypeList {
+			values, _ := cmd.Flags().GetStringSlice(key.FlagName())
+			options[key.Name] = strings.Join(values, ",")
+		} else {
+			options[key.Name] = cmd.Flags().Lookup(key.FlagName()).Value.String()
+		}
+	}
+
+	return options
+}
+
+// ensureDaemon returns a client for the daemon, starting the daemon
+// through sudo if it is not running yet. A daemon started this way exits
+// once the client it runs has stopped.
+func ensureDaemon() (*daemon.Client, error) {
+	client := daemon.NewClient("")
+	if client.IsRunning() {
+		return client, nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	// The daemon logs to a file, so that it does not keep writing to
+	// the terminal once this returns
+	args := []string{"daemon", "--idle-exit", "--log-file", daemon.DefaultLogPath}
+
+	if runtime.GOOS != "windows" && os.Geteuid() == 0 {
+		// Root needs no sudo, which servers may not even have
+		if err := daemon.StartDetached(executable, args...); err != nil {
+			return nil, fmt.Errorf("failed to start daemon: %w", err)
+		}
+	} else {
+		// sudo prompts for the password on the terminal, then runs
+		// the daemon in the background and exits
+		procCmd := exec.Command("sudo", append([]string{"-b", executable}, args...)...)
+		procCmd.Stdin = os.Stdin
+		procCmd.Stderr = os.Stderr
+		if err := procCmd.Run(); err != nil {
+			return nil, fmt.Errorf("failed to start daemon: %w", err)
+		}
+	}
+
+	deadline := time.Now().Add(daemonStartTimeout)
+	for !client.IsRunning() {
+		if time.Now().After(deadline) {
+			return nil, errors.New("timed out waiting for the daemon to start")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return client, nil
+}
+
+// applyProfile sets the flags of the command from the named profile,
+// except for flags that were given explicitly. Flags set this way count
+// as changed, so they are also passed on to the daemon.
+func applyProfile(cmd *cobra.Command, cfg *config.Config, name string) error {
+	profile, err := cfg.Profile(name)
+	if err != nil {
+		return err
+	}
+
+	if err := applyOptions(cmd, profile); err != nil {
+		return fmt.Errorf("profile %s: %w", name, err)
+	}
+
+	logger.Debug("Using profile %s", name)
+
+	return nil
+}
+
+// applyProjectConfig sets the flags of the command from the project file
+// of the current directory, if there is one, except for flags that were
+// already set. It returns the project configuration that was applied.
+func applyProjectConfig(cmd *cobra.Command) (*config.ProjectConfig, error) {
+	project, err := config.FindProjectConfig(".")
+	if err != nil || project == nil {
+		return nil, err
+	}
+
+	options := maps.Clone(project.Tunnel)
+	if options == nil {
+		options = config.Profile{}
+	}
+	if project.Org != "" {
+		options["org"] = project.Org
+	}
+
+	if err := applyOptions(cmd, options); err != nil {
+		return nil, fmt.Errorf("project %s: %w", project.Path(), err)
+	}
+
+	logger.Info("Using project file %s", project.Path())
+
+	return project, nil
+}
+
+// applyOptions sets the flags corresponding to the given profile keys,
+// except for flags that were already set. Flags set this way count as
+// changed, so they are also passed on to the daemon.
+func applyOptions(cmd *cobra.Command, options config.Profile) error {
+	for keyName, value := range options {
+		key, err := config.LookupProfileKey(keyName)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Flags().Changed(key.FlagName()) {
+			continue
+		}
+
+		if err := cmd.Flags().Set(key.FlagName(), config.FormatProfileValue(value)); err != nil {
+			return fmt.Errorf("invalid %s: %w", key.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// logDateFormat is the format of the date in rotated log file names
+const logDateFormat = "2006-01-02"
+
+// isRotatedLogFile reports whether the file name is that of a log file
+// rotated from the log file with the given base name and extension
+func isRotatedLogFile(name string, base string, ext string) bool {
+	date, ok := strings.CutPrefix(name, base+"-")
+	if !ok {
+		return false
+	}
+	date, ok = strings.CutSuffix(date, ext)
+	if !ok {
+		return false
+	}
+	_, err := time.Parse(logDateFormat, date)
+	return err == nil
+}
+
 // setupLogFile sets up file logging with rotation
 func setupLogFile(logPath string) error {
 	logDir := filepath.Dir(logPath)
@@ -546,8 +891,11 @@ func rotateLogFile(logDir string, logFile string) error {
 		return nil
 	}
 
-	// Create rotated filename with date
-	rotatedName := fmt.Sprintf("client-%s.log", fileTime.Format("2006-01-02"))
+	// Create rotated filename with date, such as client-2006-01-02.log
+	// for client.log
+	ext := filepath.Ext(logFile)
+	base := strings.TrimSuffix(filepath.Base(logFile), ext)
+	rotatedName := fmt.Sprintf("%s-%s%s", base, fileTime.Format(logDateFormat), ext)
 	rotatedPath := filepath.Join(logDir, rotatedName)
 
 	// Rename current log file to dated filename
@@ -557,12 +905,15 @@ func rotateLogFile(logDir string, logFile string) error {
 	}
 
 	// Clean up old log files (keep last 30 days)
-	cleanupOldLogFiles(logDir, 30)
+	cleanupOldLogFiles(logDir, base, ext, 30)
 	return nil
 }
 
-// cleanupOldLogFiles removes log files older than specified days
-func cleanupOldLogFiles(logDir string, daysToKeep int) {
+// cleanupOldLogFiles removes rotated log files older than specified
+// days. Only files rotated from the log file with the given base name
+// and extension are considered, so that the log files of other
+// instances in the same directory are left alone.
+func cleanupOldLogFiles(logDir string, base string, ext string, daysToKeep int) {
 	cutoff := time.Now().AddDate(0, 0, -daysToKeep)
 	files, err := os.ReadDir(logDir)
 	if err != nil {
@@ -570,7 +921,7 @@ func cleanupOldLogFiles(logDir string, daysToKeep int) {
 	}
 
 	for _, file := range files {
-		if !file.IsDir() && strings.HasPrefix(file.Name(), "client-") && strings.HasSuffix(file.Name(), ".log") {
+		if !file.IsDir() && isRotatedLogFile(file.Name(), base, ext) {
 			filePath := filepath.Join(logDir, file.Name())
 			info, err := file.Info()
 			if err != nil {
@@ -0,0 +1,226 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/fosrl/cli/internal/api"
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/daemon"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// Restart policies for attached mode
+const (
+	restartNo        = "no"
+	restartOnFailure = "on-failure"
+)
+
+const (
+	// restartInitialBackoff is the delay before the first restart,
+	// doubled on every consecutive restart
+	restartInitialBackoff = time.Second
+	// restartMaxBackoff caps the delay between restarts. A client that
+	// ran for at least this long resets the backoff.
+	restartMaxBackoff = time.Minute
+)
+
+// supervisedClient describes the client run by the supervisor
+type supervisedClient struct {
+	OlmID     string
+	OlmSecret string
+	UserToken string
+	// Endpoint and OrgID are resolved by the supervisor, since the
+	// child gets credentials rather than the account they belong to
+	Endpoint string
+	OrgID    string
+	// CredentialsFromKeyring is set when the credentials belong to
+	// the active account, so that they can be refreshed
+	CredentialsFromKeyring bool
+	// MaxRestarts is the number of restarts allowed within
+	// RestartWindow before giving up
+	MaxRestarts   int
+	RestartWindow time.Duration
+}
+
+// superviseClient runs the client in a child process with the same
+// arguments and restarts it with exponential backoff whenever it fails.
+// An authentication error refreshes the client credentials once, the
+// next one gives up.
+func superviseClient(cmd *cobra.Command, client supervisedClient) error {
+	apiClient := api.FromContext(cmd.Context())
+	accountStore := config.AccountStoreFromContext(cmd.Context())
+
+	executable, err := os.Executable()
+	if err != nil {
+		logger.Error("Error: failed to get executable path: %v", err)
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// The last occurrence of a flag wins, so the child runs the client
+	// itself instead of supervising another one, with the endpoint and
+	// organization resolved here
+	args := append(slices.Clone(os.Args[1:]), "--restart="+restartNo, "--endpoint", client.Endpoint)
+	if client.OrgID != "" {
+		args = append(args, "--org", client.OrgID)
+	}
+
+	backoff := restartInitialBackoff
+	credentialsRefreshed := false
+	var restarts []time.Time
+
+	for {
+		startedAt := time.Now()
+
+		exitCode, err := runSupervisedClient(ctx, executable, args, client)
+		if err != nil {
+			logger.Error("Error: failed to start client: %v", err)
+			return err
+		}
+
+		if ctx.Err() != nil {
+			// Stopped by a signal, which is not a failure
+			return nil
+		}
+
+		var reason string
+		switch exitCode {
+		case 0:
+			logger.Info("Client exited")
+			return nil
+		case utils.ExitCodeTerminated:
+			reason = "terminated by the server"
+		case utils.ExitCodeAuthError:
+			reason = "authentication error"
+
+			if credentialsRefreshed || !client.CredentialsFromKeyring {
+				err := utils.NewExitError(utils.ExitCodeAuthError, errors.New("client failed to authenticate, giving up"))
+				logger.Error("Error: %v", err)
+				return err
+			}
+
+			logger.Info("Refreshing client credentials")
+			if err := refreshCredentials(cmd.Context(), apiClient, accountStore, &client); err != nil {
+				logger.Error("Failed to refresh client credentials: %v", err)
+				utils.LogErrorHint(err)
+				return err
+			}
+			credentialsRefreshed = true
+		case -1:
+			reason = "was killed"
+		default:
+			reason = fmt.Sprintf("exited with status %d", exitCode)
+		}
+
+		// Only restarts within the window count towards the limit
+		now := time.Now()
+		recent := restarts[:0]
+		for _, restart := range restarts {
+			if now.Sub(restart) < client.RestartWindow {
+				recent = append(recent, restart)
+			}
+		}
+		restarts = recent
+
+		if len(restarts) >= client.MaxRestarts {
+			err := fmt.Errorf("client %s, giving up after %d restarts within %s", reason, len(restarts), client.RestartWindow)
+			logger.Error("Error: %v", err)
+			return err
+		}
+		restarts = append(restarts, now)
+
+		if now.Sub(startedAt) >= restartMaxBackoff {
+			backoff = restartInitialBackoff
+		}
+
+		logger.Warning("Client %s, restarting in %s (restart %d of %d within %s)", reason, backoff, len(restarts), client.MaxRestarts, client.RestartWindow)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, restartMaxBackoff)
+	}
+}
+
+// runSupervisedClient runs the client until it exits and returns its
+// exit code. The client is stopped when the context is canceled.
+func runSupervisedClient(ctx context.Context, executable string, args []string, client supervisedClient) (int, error) {
+	child := exec.Command(executable, args...)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	// Credentials are handed over the same way as by the daemon, so
+	// that refreshed credentials reach the restarted client
+	child.Env = append(os.Environ(),
+		daemon.OlmIDEnvVar+"="+client.OlmID,
+		daemon.OlmSecretEnvVar+"="+client.OlmSecret,
+		daemon.UserTokenEnvVar+"="+client.UserToken,
+	)
+
+	if err := child.Start(); err != nil {
+		return 0, err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = child.Process.Signal(syscall.SIGTERM)
+		case <-done:
+		}
+	}()
+
+	err := child.Wait()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+
+	return 0, err
+}
+
+// refreshCredentials verifies the client credentials of the active
+// account with the server, creating new ones if they are no longer valid
+func refreshCredentials(ctx context.Context, apiClient *api.Client, accountStore *config.AccountStore, client *supervisedClient) error {
+	activeAccount, err := accountStore.ActiveAccount()
+	if err != nil {
+		return err
+	}
+
+	newCredsGenerated, err := utils.EnsureOlmCredentials(ctx, apiClient, activeAccount)
+	if err != nil {
+		return err
+	}
+
+	if newCredsGenerated {
+		logger.Info("Created new client credentials")
+
+		accountStore.Accounts[accountStore.ActiveUserID] = *activeAccount
+		if err := accountStore.Save(); err != nil {
+			return fmt.Errorf("failed to save accounts to store: %w", err)
+		}
+	}
+
+	client.OlmID = activeAccount.OlmCredentials.ID
+	client.OlmSecret = activeAccount.OlmCredentials.Secret
+
+	return nil
+}
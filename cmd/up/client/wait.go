@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fosrl/cli/internal/daemon"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/olm"
+	"github.com/fosrl/cli/internal/utils"
+)
+
+// waitPollInterval is how often the client status is polled while waiting
+const waitPollInterval = 500 * time.Millisecond
+
+// waitForClient polls the client started by the daemon until it is
+// registered and the given sites are connected. Sites are matched by
+// name or ID. The returned error carries the exit code telling why the
+// client did not become ready.
+func waitForClient(ctx context.Context, olmClient *olm.Client, daemonClient *daemon.Client, name string, timeout time.Duration, sites []string, silent bool) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+
+	// Progress is only logged when it changes
+	lastProgress := ""
+	progress := func(format string, args ...any) {
+		message := fmt.Sprintf(format, args...)
+		if !silent && message != lastProgress {
+			logger.Info("%s", message)
+		}
+		lastProgress = message
+	}
+
+	for {
+		status, err := olmClient.GetStatus()
+		switch {
+		case err != nil:
+			// The client socket is not available until the client has
+			// started, so only give up once the daemon reports that the
+			// client exited
+			if err := clientExitError(daemonClient, name); err != nil {
+				return err
+			}
+			progress("Waiting for the client to start")
+		case status.Terminated:
+			return utils.NewExitError(utils.ExitCodeTerminated, errors.New("client was terminated by the server"))
+		case !status.Registered:
+			progress("Waiting for the client to register")
+		default:
+			missing := missingSites(status, sites)
+			if len(missing) == 0 {
+				return nil
+			}
+			progress("Waiting for sites to connect: %s", strings.Join(missing, ", "))
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return utils.NewExitError(utils.ExitCodeTimeout, fmt.Errorf("timed out after %s: %s", timeout, strings.ToLower(lastProgress)))
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// clientExitError returns an error if the daemon reports that the client
+// of the instance is no longer running, with the exit code matching how
+// the client exited
+func clientExitError(daemonClient *daemon.Client, name string) error {
+	status, err := daemonClient.Status(name)
+	if err != nil {
+		// A daemon that exits when idle stops shortly after its client
+		// exited, so the reason is no longer known
+		if !daemonClient.IsRunning() {
+			return errors.New("client exited before it was ready")
+		}
+		return nil
+	}
+
+	if status.Running || status.LastExitCode == nil {
+		return nil
+	}
+
+	switch code := *status.LastExitCode; code {
+	case utils.ExitCodeTerminated:
+		return utils.NewExitError(utils.ExitCodeTerminated, errors.New("client was terminated by the server"))
+	case utils.ExitCodeAuthError:
+		return utils.NewExitError(utils.ExitCodeAuthError, errors.New("client failed to authenticate"))
+	default:
+		return fmt.Errorf("client exited with status %d before it was ready", code)
+	}
+}
+
+// missingSites returns the sites that are not connected, in the order
+// they were given. Sites are matched by name or ID.
+func missingSites(status *olm.StatusResponse, sites []string) []string {
+	connected := map[string]bool{}
+	for _, peer := range status.PeerStatuses {
+		if peer.Connected {
+			connected[peer.SiteName] = true
+			connected[strconv.Itoa(peer.SiteID)] = true
+		}
+	}
+
+	var missing []string
+	for _, site := range sites {
+		if !connected[site] {
+			missing = append(missing, site)
+		}
+	}
+	return missing
+}
@@ -2,15 +2,24 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 )
 
+const (
+	// defaultBaseURL is used when no base URL is configured
+	defaultBaseURL = "https://app.pangolin.net"
+	// defaultCSRFToken is sent when the client has no CSRF token configured
+	defaultCSRFToken = "x-csrf-protection"
+)
+
 // ClientConfig holds configuration for creating a new client
 type ClientConfig struct {
 	BaseURL           string
@@ -19,16 +28,20 @@ type ClientConfig struct {
 	APIKey            string
 	SessionCookieName string
 	CSRFToken         string
+	// Timeout is the overall timeout for a single API call, including
+	// retries. Defaults to 30 seconds.
+	Timeout time.Duration
+	// Retry configures retries of failed requests. If nil,
+	// DefaultRetryPolicy is used.
+	Retry *RetryPolicy
+	// Transport is the base transport for requests. If nil, the
+	// default transport set through SetDefaultTransport is used.
+	Transport http.RoundTripper
 }
 
 // NewClient creates a new API client with the provided configuration
 func NewClient(config ClientConfig) (*Client, error) {
-	baseURL := config.BaseURL
-	if baseURL == "" {
-		baseURL = "https://app.pangolin.net"
-	} else if !strings.HasPrefix(baseURL, "http") {
-		baseURL = "https://" + baseURL
-	}
+	baseURL := normalizeBaseURL(config.BaseURL)
 
 	// Default session cookie name
 	sessionCookieName := config.SessionCookieName
@@ -36,15 +49,28 @@ func NewClient(config ClientConfig) (*Client, error) {
 		sessionCookieName = "p_session_token"
 	}
 
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	retryPolicy := DefaultRetryPolicy()
+	if config.Retry != nil {
+		retryPolicy = *config.Retry
+	}
+
 	client := &Client{
-		BaseURL:           strings.TrimSuffix(baseURL, "/"),
+		BaseURL:           baseURL,
 		AgentName:         config.AgentName,
 		APIKey:            config.APIKey,
 		Token:             config.Token,
 		SessionCookieName: sessionCookieName,
 		CSRFToken:         config.CSRFToken,
-		HTTPClient: &HTTPClient{
-			Timeout: 30 * time.Second,
+		HTTPClient: &http.Client{
+			Timeout: timeout,
+			// Tracing sits below the retry layer so that every
+			// attempt is logged individually
+			Transport: newRetryTransport(NewTraceTransport(config.Transport), retryPolicy),
 		},
 	}
 
@@ -52,32 +78,39 @@ func NewClient(config ClientConfig) (*Client, error) {
 }
 
 // Get performs a GET request to the API
-func (c *Client) Get(endpoint string, result interface{}, opts ...RequestOptions) error {
-	return c.request(http.MethodGet, endpoint, nil, result, opts...)
+func (c *Client) Get(ctx context.Context, endpoint string, result interface{}, opts ...RequestOptions) error {
+	return c.request(ctx, http.MethodGet, endpoint, nil, result, opts...)
 }
 
 // Post performs a POST request to the API
-func (c *Client) Post(endpoint string, payload interface{}, result interface{}, opts ...RequestOptions) error {
-	return c.request(http.MethodPost, endpoint, payload, result, opts...)
+func (c *Client) Post(ctx context.Context, endpoint string, payload interface{}, result interface{}, opts ...RequestOptions) error {
+	return c.request(ctx, http.MethodPost, endpoint, payload, result, opts...)
 }
 
 // Put performs a PUT request to the API
-func (c *Client) Put(endpoint string, payload interface{}, result interface{}, opts ...RequestOptions) error {
-	return c.request(http.MethodPut, endpoint, payload, result, opts...)
+func (c *Client) Put(ctx context.Context, endpoint string, payload interface{}, result interface{}, opts ...RequestOptions) error {
+	return c.request(ctx, http.MethodPut, endpoint, payload, result, opts...)
 }
 
 // Patch performs a PATCH request to the API
-func (c *Client) Patch(endpoint string, payload interface{}, result interface{}, opts ...RequestOptions) error {
-	return c.request(http.MethodPatch, endpoint, payload, result, opts...)
+func (c *Client) Patch(ctx context.Context, endpoint string, payload interface{}, result interface{}, opts ...RequestOptions) error {
+	return c.request(ctx, http.MethodPatch, endpoint, payload, result, opts...)
 }
 
 // Delete performs a DELETE request to the API
-func (c *Client) Delete(endpoint string, result interface{}, opts ...RequestOptions) error {
-	return c.request(http.MethodDelete, endpoint, nil, result, opts...)
+func (c *Client) Delete(ctx context.Context, endpoint string, result interface{}, opts ...RequestOptions) error {
+	return c.request(ctx, http.MethodDelete, endpoint, nil, result, opts...)
 }
 
-// request is the core method that handles all HTTP requests
-func (c *Client) request(method, endpoint string, payload interface{}, result interface{}, opts ...RequestOptions) error {
+// request is the core method that handles all HTTP requests. Every
+// call to the API, including the unauthenticated login and device
+// authorization helpers, goes through this pipeline.
+func (c *Client) request(ctx context.Context, method, endpoint string, payload interface{}, result interface{}, opts ...RequestOptions) error {
+	var options RequestOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
 	// Build URL
 	requestURL, err := c.buildURL(endpoint, opts...)
 	if err != nil {
@@ -95,26 +128,25 @@ func (c *Client) request(method, endpoint string, payload interface{}, result in
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequest(method, requestURL, body)
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set default headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-
-	// Set User-Agent with agent name
-	userAgent := c.AgentName
-	if userAgent == "" {
-		userAgent = "pangolin-cli"
+	userAgent := getUserAgent(c.AgentName)
+	if payload != nil {
+		setJSONRequestHeaders(req, userAgent)
+	} else {
+		setJSONResponseHeaders(req, userAgent)
 	}
-	req.Header.Set("User-Agent", userAgent)
 
-	// Set CSRF header if provided
-	if c.CSRFToken != "" {
-		req.Header.Set("X-CSRF-Token", c.CSRFToken)
+	// Set CSRF header
+	csrfToken := c.CSRFToken
+	if csrfToken == "" {
+		csrfToken = defaultCSRFToken
 	}
+	req.Header.Set("X-CSRF-Token", csrfToken)
 
 	// Set authentication
 	if c.Token != "" {
@@ -130,20 +162,18 @@ func (c *Client) request(method, endpoint string, payload interface{}, result in
 	}
 
 	// Apply custom headers from options
-	if len(opts) > 0 && opts[0].Headers != nil {
-		for key, value := range opts[0].Headers {
-			req.Header.Set(key, value)
-		}
-	}
-
-	// Create HTTP client and execute request
-	httpClient := &http.Client{
-		Timeout: c.HTTPClient.Timeout,
+	for key, value := range options.Headers {
+		req.Header.Set(key, value)
 	}
 
-	resp, err := httpClient.Do(req)
+	// Execute request through the shared retrying transport
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		// Cancellation by the caller is not a network failure
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return &NetworkError{Method: method, Path: endpoint, Err: err}
 	}
 	defer resp.Body.Close()
 
@@ -153,41 +183,44 @@ func (c *Client) request(method, endpoint string, payload interface{}, result in
 		return fmt.Errorf("failed to read response: %w", err)
 	}
 
+	raw := options.Response
+	if raw == nil {
+		raw = &RawResponse{}
+	}
+	raw.StatusCode = resp.StatusCode
+	raw.Header = resp.Header
+	raw.Cookies = resp.Cookies()
+	raw.Body = bodyBytes
+
+	newError := func(apiResp *APIResponse) error {
+		errorResp := createErrorResponse(apiResp, resp.StatusCode, options.ErrorMessages)
+		errorResp.Method = method
+		errorResp.Path = strings.SplitN(endpoint, "?", 2)[0]
+		return errorResp
+	}
+
 	if len(bodyBytes) == 0 {
+		if resp.StatusCode >= http.StatusBadRequest {
+			return newError(&APIResponse{})
+		}
 		return nil
 	}
 
 	// Parse the API response structure
-	var apiResp APIResponse
-	if err := json.Unmarshal(bodyBytes, &apiResp); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
+	apiResp, err := parseAPIResponseBody(bodyBytes)
+	if err != nil {
+		// Proxies and load balancers in front of the server may answer
+		// errors with non-JSON bodies, so fall back to the status code.
+		if resp.StatusCode >= http.StatusBadRequest {
+			return newError(&APIResponse{})
+		}
+		return err
 	}
+	raw.Message = apiResp.Message
 
 	// Check if the response indicates an error (based on error/success fields)
 	if apiResp.Error.Bool() || !apiResp.Success {
-		errorResp := ErrorResponse{
-			Message: apiResp.Message,
-			Status:  apiResp.Status,
-			Stack:   apiResp.Stack,
-		}
-		// Use HTTP status code if status field is not set
-		if errorResp.Status == 0 {
-			errorResp.Status = resp.StatusCode
-		}
-		// If message is empty, try to provide a default based on status code
-		if errorResp.Message == "" {
-			switch errorResp.Status {
-			case 401, 403:
-				errorResp.Message = "Unauthorized"
-			case 404:
-				errorResp.Message = "Not found"
-			case 500:
-				errorResp.Message = "Internal server error"
-			default:
-				errorResp.Message = "An error occurred"
-			}
-		}
-		return &errorResp
+		return newError(apiResp)
 	}
 
 	// Parse successful response
@@ -207,11 +240,10 @@ func (c *Client) buildURL(endpoint string, opts ...RequestOptions) (string, erro
 		endpoint = "/" + endpoint
 	}
 
-	baseURL := strings.TrimSuffix(c.BaseURL, "/")
-	fullURL := baseURL + endpoint
+	fullURL := buildAPIBaseURL(c.BaseURL) + endpoint
 
 	// Add query parameters if provided
-	if len(opts) > 0 && opts[0].Query != nil && len(opts[0].Query) > 0 {
+	if len(opts) > 0 && len(opts[0].Query) > 0 {
 		u, err := url.Parse(fullURL)
 		if err != nil {
 			return "", err
@@ -230,10 +262,7 @@ func (c *Client) buildURL(endpoint string, opts ...RequestOptions) (string, erro
 
 // SetBaseURL updates the base URL for the client
 func (c *Client) SetBaseURL(baseURL string) {
-	if !strings.HasPrefix(baseURL, "http") {
-		baseURL = "https://" + baseURL
-	}
-	c.BaseURL = strings.TrimSuffix(baseURL, "/")
+	c.BaseURL = normalizeBaseURL(baseURL)
 }
 
 // SetToken updates the token for the client
@@ -242,9 +271,9 @@ func (c *Client) SetToken(token string) {
 }
 
 // Logout logs out the current user
-func (c *Client) Logout() error {
+func (c *Client) Logout(ctx context.Context) error {
 	var result EmptyResponse
-	err := c.Post("/auth/logout", nil, &result)
+	err := c.Post(ctx, "/auth/logout", nil, &result)
 	if err != nil {
 		return err
 	}
@@ -252,34 +281,60 @@ func (c *Client) Logout() error {
 }
 
 // GetUser retrieves the current user information
-func (c *Client) GetUser() (*User, error) {
+func (c *Client) GetUser(ctx context.Context) (*User, error) {
 	var user User
-	err := c.Get("/user", &user)
+	err := c.Get(ctx, "/user", &user)
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-// ListUserOrgs lists organizations for a user
-func (c *Client) ListUserOrgs(userID string) (*ListUserOrgsResponse, error) {
+// ListUserOrgs lists organizations for a user.
+// Only the first page is returned; use UserOrgs to walk all of them.
+func (c *Client) ListUserOrgs(ctx context.Context, userID string) (*ListUserOrgsResponse, error) {
+	path := fmt.Sprintf("/user/%s/orgs", userID)
+	var response ListUserOrgsResponse
+	err := c.Get(ctx, path, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// ListUserOrgsPage lists a single page of organizations for a user
+func (c *Client) ListUserOrgsPage(ctx context.Context, userID string, limit, offset int) (*ListUserOrgsResponse, error) {
 	path := fmt.Sprintf("/user/%s/orgs", userID)
 	var response ListUserOrgsResponse
-	err := c.Get(path, &response)
+	err := c.Get(ctx, path, &response, RequestOptions{
+		Query: paginationQuery(limit, offset),
+	})
 	if err != nil {
 		return nil, err
 	}
 	return &response, nil
 }
 
+// UserOrgs returns an iterator over all organizations for a user,
+// fetching further pages as needed
+func (c *Client) UserOrgs(ctx context.Context, userID string) iter.Seq2[Org, error] {
+	return Paginate(ctx, 0, func(ctx context.Context, limit, offset int) ([]Org, Pagination, error) {
+		response, err := c.ListUserOrgsPage(ctx, userID, limit, offset)
+		if err != nil {
+			return nil, Pagination{}, err
+		}
+		return response.Orgs, response.Pagination, nil
+	})
+}
+
 // CreateOlm creates an OLM for a user
-func (c *Client) CreateOlm(userID, name string) (*CreateOlmResponse, error) {
+func (c *Client) CreateOlm(ctx context.Context, userID, name string) (*CreateOlmResponse, error) {
 	requestBody := CreateOlmRequest{
 		Name: name,
 	}
 	path := fmt.Sprintf("/user/%s/olm", userID)
 	var response CreateOlmResponse
-	err := c.Put(path, requestBody, &response)
+	err := c.Put(ctx, path, requestBody, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -287,10 +342,10 @@ func (c *Client) CreateOlm(userID, name string) (*CreateOlmResponse, error) {
 }
 
 // GetUserOlm gets an OLM for a user by userId and olmId
-func (c *Client) GetUserOlm(userID, olmID string) (*Olm, error) {
+func (c *Client) GetUserOlm(ctx context.Context, userID, olmID string) (*Olm, error) {
 	path := fmt.Sprintf("/user/%s/olm/%s", userID, olmID)
 	var olm Olm
-	err := c.Get(path, &olm)
+	err := c.Get(ctx, path, &olm)
 	if err != nil {
 		return nil, err
 	}
@@ -298,10 +353,10 @@ func (c *Client) GetUserOlm(userID, olmID string) (*Olm, error) {
 }
 
 // GetOrg gets an organization by ID
-func (c *Client) GetOrg(orgID string) (*GetOrgResponse, error) {
+func (c *Client) GetOrg(ctx context.Context, orgID string) (*GetOrgResponse, error) {
 	path := fmt.Sprintf("/org/%s", orgID)
 	var response GetOrgResponse
-	err := c.Get(path, &response)
+	err := c.Get(ctx, path, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -309,10 +364,10 @@ func (c *Client) GetOrg(orgID string) (*GetOrgResponse, error) {
 }
 
 // CheckOrgUserAccess checks if a user has access to an organization
-func (c *Client) CheckOrgUserAccess(orgID, userID string) (*CheckOrgUserAccessResponse, error) {
+func (c *Client) CheckOrgUserAccess(ctx context.Context, orgID, userID string) (*CheckOrgUserAccessResponse, error) {
 	path := fmt.Sprintf("/org/%s/user/%s/check", orgID, userID)
 	var response CheckOrgUserAccessResponse
-	err := c.Get(path, &response)
+	err := c.Get(ctx, path, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -320,10 +375,10 @@ func (c *Client) CheckOrgUserAccess(orgID, userID string) (*CheckOrgUserAccessRe
 }
 
 // GetClient gets a client by ID
-func (c *Client) GetClient(clientID int) (*GetClientResponse, error) {
+func (c *Client) GetClient(ctx context.Context, clientID int) (*GetClientResponse, error) {
 	path := fmt.Sprintf("/client/%d", clientID)
 	var response GetClientResponse
-	err := c.Get(path, &response)
+	err := c.Get(ctx, path, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -331,13 +386,11 @@ func (c *Client) GetClient(clientID int) (*GetClientResponse, error) {
 }
 
 // GetMyDevice gets the current device information including user, organizations, and OLM
-func (c *Client) GetMyDevice(olmID string) (*MyDeviceResponse, error) {
-	// Build query parameters
-	params := url.Values{}
-	params.Set("olmId", olmID)
-	path := fmt.Sprintf("/my-device?%s", params.Encode())
+func (c *Client) GetMyDevice(ctx context.Context, olmID string) (*MyDeviceResponse, error) {
 	var response MyDeviceResponse
-	err := c.Get(path, &response)
+	err := c.Get(ctx, "/my-device", &response, RequestOptions{
+		Query: map[string]string{"olmId": olmID},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -345,15 +398,14 @@ func (c *Client) GetMyDevice(olmID string) (*MyDeviceResponse, error) {
 }
 
 // TestConnection tests the connection to the API server
-func (c *Client) TestConnection() (bool, error) {
-	// Create a temporary client with shorter timeout for connection test
-	testClient := &http.Client{
-		Timeout: 10 * time.Second,
-	}
+func (c *Client) TestConnection(ctx context.Context) (bool, error) {
+	// Use a shorter timeout for the connection test
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
 
 	// Use HEAD request to test connection
 	fullURL := c.BaseURL
-	req, err := http.NewRequest("HEAD", fullURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "HEAD", fullURL, nil)
 	if err != nil {
 		return false, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -364,7 +416,7 @@ func (c *Client) TestConnection() (bool, error) {
 	}
 	req.Header.Set("User-Agent", userAgent)
 
-	resp, err := testClient.Do(req)
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return false, nil // Return false (not an error) if connection fails
 	}
@@ -379,7 +431,7 @@ func (c *Client) TestConnection() (bool, error) {
 // normalizeBaseURL normalizes a base URL by adding protocol if missing and trimming trailing slashes
 func normalizeBaseURL(baseURL string) string {
 	if baseURL == "" {
-		baseURL = "https://app.pangolin.net"
+		baseURL = defaultBaseURL
 	}
 	if !strings.HasPrefix(baseURL, "http") {
 		baseURL = "https://" + baseURL
@@ -426,13 +478,6 @@ func setJSONResponseHeaders(req *http.Request, userAgent string) {
 	req.Header.Set("User-Agent", userAgent)
 }
 
-// createHTTPClient creates an HTTP client with the specified timeout
-func createHTTPClient(timeout time.Duration) *http.Client {
-	return &http.Client{
-		Timeout: timeout,
-	}
-}
-
 // parseAPIResponseBody parses the response body into an APIResponse struct
 func parseAPIResponseBody(bodyBytes []byte) (*APIResponse, error) {
 	var apiResp APIResponse
@@ -442,8 +487,9 @@ func parseAPIResponseBody(bodyBytes []byte) (*APIResponse, error) {
 	return &apiResp, nil
 }
 
-// createErrorResponse creates an ErrorResponse from an APIResponse and HTTP status code
-func createErrorResponse(apiResp *APIResponse, httpStatusCode int, getDefaultMessage func(int) string) *ErrorResponse {
+// createErrorResponse creates an ErrorResponse from an APIResponse and HTTP status code.
+// Messages for specific status codes can be overridden per endpoint through errorMessages.
+func createErrorResponse(apiResp *APIResponse, httpStatusCode int, errorMessages map[int]string) *ErrorResponse {
 	errorResp := ErrorResponse{
 		Message: apiResp.Message,
 		Status:  apiResp.Status,
@@ -454,8 +500,12 @@ func createErrorResponse(apiResp *APIResponse, httpStatusCode int, getDefaultMes
 		errorResp.Status = httpStatusCode
 	}
 
-	if errorResp.Message == "" && getDefaultMessage != nil {
-		errorResp.Message = getDefaultMessage(errorResp.Status)
+	if errorResp.Message == "" {
+		if message, ok := errorMessages[errorResp.Status]; ok {
+			errorResp.Message = message
+		} else {
+			errorResp.Message = getDefaultErrorMessage(errorResp.Status)
+		}
 	}
 
 	return &errorResp
@@ -479,245 +529,59 @@ func getDefaultErrorMessage(statusCode int) string {
 	}
 }
 
-// getDeviceAuthErrorMessage returns a default error message for device auth endpoints
-func getDeviceAuthErrorMessage(statusCode int) string {
-	switch statusCode {
-	case 400:
-		return "Bad request"
-	case 403:
-		return "IP address mismatch"
-	case 429:
-		return "Rate limit exceeded"
-	case 500:
-		return "Internal server error"
-	default:
-		return "An error occurred"
-	}
+// deviceAuthErrorMessages overrides default error messages for device auth endpoints
+var deviceAuthErrorMessages = map[int]string{
+	403: "IP address mismatch",
 }
 
 // LoginWithCookie performs a login request and returns the session cookie
 // This is a lower-level function that handles cookie extraction
-func LoginWithCookie(client *Client, req LoginRequest) (*LoginResponse, string, error) {
+func LoginWithCookie(ctx context.Context, client *Client, req LoginRequest) (*LoginResponse, string, error) {
 	var response LoginResponse
-	sessionToken := ""
-
-	// Build URL
-	baseURL := normalizeBaseURL(client.BaseURL)
-	endpoint := "/api/v1/auth/login"
-	url := baseURL + endpoint
-
-	// Marshal request body
-	jsonData, err := json.Marshal(req)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create request
-	httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	userAgent := getUserAgent(client.AgentName)
-	setJSONRequestHeaders(httpReq, userAgent)
-
-	// Set CSRF token header
-	csrfToken := client.CSRFToken
-	if csrfToken == "" {
-		csrfToken = "x-csrf-protection"
-	}
-	httpReq.Header.Set("X-CSRF-Token", csrfToken)
+	var raw RawResponse
 
-	// Execute request
-	httpClient := createHTTPClient(client.HTTPClient.Timeout)
-	resp, err := httpClient.Do(httpReq)
-	if err != nil {
-		return nil, "", fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Extract session cookie
-	for _, cookie := range resp.Cookies() {
-		if cookie.Name == client.SessionCookieName || cookie.Name == "p_session" {
-			sessionToken = cookie.Value
-			break
-		}
-	}
-
-	// Read response body
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if len(bodyBytes) == 0 {
-		// Return empty response but with token if available
-		return &response, sessionToken, nil
-	}
-
-	// Parse the API response
-	apiResp, err := parseAPIResponseBody(bodyBytes)
+	err := client.Post(ctx, "/auth/login", req, &response, RequestOptions{
+		Response: &raw,
+	})
 	if err != nil {
 		return nil, "", err
 	}
 
-	// Check if the response indicates an error
-	if apiResp.Error.Bool() || !apiResp.Success {
-		// Try to extract message from raw response if it exists in a different format
-		if apiResp.Message == "" {
-			var rawResp map[string]interface{}
-			if json.Unmarshal(bodyBytes, &rawResp) == nil {
-				if msg, ok := rawResp["message"].(string); ok && msg != "" {
-					apiResp.Message = msg
-				}
-			}
-		}
-
-		errorResp := createErrorResponse(apiResp, resp.StatusCode, getDefaultErrorMessage)
-		return nil, "", errorResp
-	}
-
-	// Parse successful response data
-	if apiResp.Data != nil {
-		if err := json.Unmarshal(apiResp.Data, &response); err != nil {
-			return nil, "", fmt.Errorf("failed to unmarshal response data: %w", err)
-		}
-	}
+	// Extract session cookie
+	sessionToken := raw.Cookie(client.SessionCookieName, "p_session")
 
 	return &response, sessionToken, nil
 }
 
 // StartDeviceWebAuth requests a device code from the server
 // The client should have BaseURL set but no authentication token is required
-func StartDeviceWebAuth(client *Client, req DeviceWebAuthStartRequest) (*DeviceWebAuthStartResponse, error) {
+func StartDeviceWebAuth(ctx context.Context, client *Client, req DeviceWebAuthStartRequest) (*DeviceWebAuthStartResponse, error) {
 	var response DeviceWebAuthStartResponse
 
-	// Build URL
-	baseURL := buildAPIBaseURL(client.BaseURL)
-	endpoint := "/auth/device-web-auth/start"
-	url := baseURL + endpoint
-
-	// Marshal request body
-	jsonData, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create request
-	httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	userAgent := getUserAgent(client.AgentName)
-	setJSONRequestHeaders(httpReq, userAgent)
-
-	// Set CSRF token header
-	csrfToken := client.CSRFToken
-	if csrfToken == "" {
-		csrfToken = "x-csrf-protection"
-	}
-	httpReq.Header.Set("X-CSRF-Token", csrfToken)
-
-	// Execute request
-	httpClient := createHTTPClient(client.HTTPClient.Timeout)
-	resp, err := httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Parse the API response
-	apiResp, err := parseAPIResponseBody(bodyBytes)
+	err := client.Post(ctx, "/auth/device-web-auth/start", req, &response, RequestOptions{
+		ErrorMessages: deviceAuthErrorMessages,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if the response indicates an error
-	if apiResp.Error.Bool() || !apiResp.Success {
-		errorResp := createErrorResponse(apiResp, resp.StatusCode, getDeviceAuthErrorMessage)
-		return nil, errorResp
-	}
-
-	// Parse successful response data
-	if apiResp.Data != nil {
-		if err := json.Unmarshal(apiResp.Data, &response); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal response data: %w", err)
-		}
-	}
-
 	return &response, nil
 }
 
 // PollDeviceWebAuth polls the server to check if the device code has been verified
 // The client should have BaseURL set but no authentication token is required
-func PollDeviceWebAuth(client *Client, code string) (*DeviceWebAuthPollResponse, string, error) {
+func PollDeviceWebAuth(ctx context.Context, client *Client, code string) (*DeviceWebAuthPollResponse, string, error) {
 	var response DeviceWebAuthPollResponse
+	var raw RawResponse
 
-	// Build URL
-	baseURL := buildAPIBaseURL(client.BaseURL)
-	endpoint := fmt.Sprintf("/auth/device-web-auth/poll/%s", code)
-	url := baseURL + endpoint
-
-	// Create request
-	httpReq, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	userAgent := getUserAgent(client.AgentName)
-	setJSONResponseHeaders(httpReq, userAgent)
-
-	// Set CSRF token header
-	csrfToken := client.CSRFToken
-	if csrfToken == "" {
-		csrfToken = "x-csrf-protection"
-	}
-	httpReq.Header.Set("X-CSRF-Token", csrfToken)
-
-	// Execute request
-	httpClient := createHTTPClient(client.HTTPClient.Timeout)
-	resp, err := httpClient.Do(httpReq)
-	if err != nil {
-		return nil, "", fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Parse the API response
-	apiResp, err := parseAPIResponseBody(bodyBytes)
+	path := fmt.Sprintf("/auth/device-web-auth/poll/%s", url.PathEscape(code))
+	err := client.Get(ctx, path, &response, RequestOptions{
+		ErrorMessages: deviceAuthErrorMessages,
+		Response:      &raw,
+	})
 	if err != nil {
-		return nil, "", err
-	}
-
-	message := apiResp.Message
-
-	// Check if the response indicates an error
-	if apiResp.Error.Bool() || !apiResp.Success {
-		errorResp := createErrorResponse(apiResp, resp.StatusCode, getDeviceAuthErrorMessage)
-		return nil, message, errorResp
-	}
-
-	// Parse successful response data
-	if apiResp.Data != nil {
-		if err := json.Unmarshal(apiResp.Data, &response); err != nil {
-			return nil, message, fmt.Errorf("failed to unmarshal response data: %w", err)
-		}
+		return nil, raw.Message, err
 	}
 
-	return &response, message, nil
+	return &response, raw.Message, nil
 }
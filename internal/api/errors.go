@@ -0,0 +1,97 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors for classifying failed API calls with errors.Is
+var (
+	// ErrUnauthorized indicates that the session or API key is missing,
+	// expired or invalid
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden indicates that the authenticated user is not allowed
+	// to perform the request
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound indicates that the requested resource does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrRateLimited indicates that the server is rate limiting requests
+	ErrRateLimited = errors.New("rate limited")
+	// ErrServer indicates that the server failed to process the request
+	ErrServer = errors.New("server error")
+	// ErrPolicyViolation indicates that an organization policy prevents
+	// the user from connecting
+	ErrPolicyViolation = errors.New("organization policy is preventing you from connecting")
+	// ErrNetwork indicates that the server could not be reached
+	ErrNetwork = errors.New("network error")
+)
+
+// ErrorResponse represents an API error response
+type ErrorResponse struct {
+	Message string `json:"message"`
+	Status  int    `json:"status"`
+	Stack   string `json:"stack,omitempty"`
+
+	// Method and Path identify the request that failed
+	Method string `json:"-"`
+	Path   string `json:"-"`
+}
+
+// Error implements the error interface
+// Returns the message if present, otherwise the status code, followed
+// by the request that failed
+func (e *ErrorResponse) Error() string {
+	message := e.Message
+	if message == "" {
+		// If no message, use just the status code
+		message = fmt.Sprintf("%d", e.Status)
+	}
+
+	if e.Method == "" {
+		return message
+	}
+	return fmt.Sprintf("%s (%s %s)", message, e.Method, e.Path)
+}
+
+// Is reports whether the error matches one of the sentinel errors
+// based on its status code
+func (e *ErrorResponse) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.Status == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.Status == http.StatusForbidden
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound
+	case ErrRateLimited:
+		return e.Status == http.StatusTooManyRequests
+	case ErrServer:
+		return e.Status >= http.StatusInternalServerError
+	default:
+		return false
+	}
+}
+
+// NetworkError is returned when a request could not be completed
+// because the server was unreachable or the connection failed
+type NetworkError struct {
+	Method string
+	Path   string
+	Err    error
+}
+
+// Error implements the error interface
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("request failed (%s %s): %v", e.Method, e.Path, e.Err)
+}
+
+// Unwrap returns the underlying transport error
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrNetwork
+func (e *NetworkError) Is(target error) bool {
+	return target == ErrNetwork
+}
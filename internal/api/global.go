@@ -2,28 +2,36 @@ package api
 
 import (
 	"fmt"
-	"strings"
 )
 
 // InitClient initializes a new API client with stored credentials and
 // a URL. The client will be created without authentication if no token
 // is found.
 func InitClient(hostname string, token string) (*Client, error) {
-	// Build base URL (hostname should already include protocol from login)
-	baseURL := hostname
-	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
-		// If no protocol, default to https
-		baseURL = "https://" + baseURL
-	}
-	baseURL = strings.TrimSuffix(baseURL, "/") + "/api/v1"
-
 	// Create API client (this should never fail, but handle it just in case)
 	client, err := NewClient(ClientConfig{
-		BaseURL:           baseURL,
+		BaseURL:           buildAPIBaseURL(hostname),
 		AgentName:         "pangolin-cli",
 		Token:             token,
 		SessionCookieName: "p_session_token",
-		CSRFToken:         "x-csrf-protection",
+		CSRFToken:         defaultCSRFToken,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	return client, nil
+}
+
+// InitClientWithAPIKey initializes a new API client that authenticates
+// with an org or root API key instead of a session token.
+func InitClientWithAPIKey(hostname string, apiKey string) (*Client, error) {
+	client, err := NewClient(ClientConfig{
+		BaseURL:           buildAPIBaseURL(hostname),
+		AgentName:         "pangolin-cli",
+		APIKey:            apiKey,
+		SessionCookieName: "p_session_token",
+		CSRFToken:         defaultCSRFToken,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create API client: %w", err)
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// TransportConfig holds network settings for HTTP connections made by
+// the CLI, such as corporate proxies and custom certificate authorities
+type TransportConfig struct {
+	// HTTPSProxy is the proxy URL for HTTPS requests. If empty, the
+	// HTTPS_PROXY environment variable is used.
+	HTTPSProxy string
+	// NoProxy is a comma-separated list of hosts that bypass the proxy.
+	// If empty, the NO_PROXY environment variable is used.
+	NoProxy string
+	// CAFiles are PEM files with additional trusted CA certificates
+	CAFiles []string
+	// ClientCert and ClientKey are PEM files with a client certificate
+	// and its private key for mutual TLS
+	ClientCert string
+	ClientKey  string
+	// InsecureSkipVerify disables server certificate verification
+	InsecureSkipVerify bool
+}
+
+// NewTransport builds an HTTP transport from the given network settings
+func NewTransport(cfg TransportConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	// Resolve the proxy settings explicitly instead of relying on
+	// http.ProxyFromEnvironment, so configured values take precedence
+	// over the environment.
+	proxyConfig := httpproxy.FromEnvironment()
+	if cfg.HTTPSProxy != "" {
+		proxyConfig.HTTPSProxy = cfg.HTTPSProxy
+	}
+	if cfg.NoProxy != "" {
+		proxyConfig.NoProxy = cfg.NoProxy
+	}
+	if proxyConfig.HTTPSProxy != "" {
+		if _, err := url.Parse(proxyConfig.HTTPSProxy); err != nil {
+			return nil, fmt.Errorf("invalid HTTPS proxy URL: %w", err)
+		}
+	}
+	proxyFunc := proxyConfig.ProxyFunc()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if len(cfg.CAFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		for _, caFile := range cfg.CAFiles {
+			pem, err := os.ReadFile(caFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in CA file %s", caFile)
+			}
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if (cfg.ClientCert == "") != (cfg.ClientKey == "") {
+		return nil, errors.New("client certificate and key must be provided together")
+	}
+
+	if cfg.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"iter"
+	"strconv"
+)
+
+// defaultPageSize is the number of items requested per page when
+// walking a paginated list endpoint
+const defaultPageSize = 100
+
+// Pagination represents the pagination block returned by list endpoints
+type Pagination struct {
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// PageFunc fetches a single page of a list endpoint
+type PageFunc[T any] func(ctx context.Context, limit, offset int) ([]T, Pagination, error)
+
+// Paginate returns an iterator over all items of a paginated list endpoint.
+// It requests pages of pageSize items (or a default size if pageSize is not
+// positive), following limit/offset until the reported total is reached.
+// If fetching a page fails, the error is yielded and iteration stops.
+func Paginate[T any](ctx context.Context, pageSize int, fetch PageFunc[T]) iter.Seq2[T, error] {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	return func(yield func(T, error) bool) {
+		offset := 0
+		for {
+			items, pagination, err := fetch(ctx, pageSize, offset)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			// Advance by the number of items actually returned, since
+			// the server may cap the page size below the requested limit.
+			offset += len(items)
+			if len(items) == 0 || offset >= pagination.Total {
+				return
+			}
+		}
+	}
+}
+
+// CollectAll gathers all items from a paginated iterator into a slice,
+// stopping at the first error
+func CollectAll[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// paginationQuery builds the query parameters for requesting a page
+func paginationQuery(limit, offset int) map[string]string {
+	return map[string]string{
+		"limit":  strconv.Itoa(limit),
+		"offset": strconv.Itoa(offset),
+	}
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fosrl/cli/internal/logger"
+)
+
+const (
+	// redacted replaces secret values in traces
+	redacted = "[REDACTED]"
+	// maxTraceBodySize limits how much of a body is included in a trace
+	maxTraceBodySize = 4096
+)
+
+// traceBodies controls whether request and response bodies are traced
+var traceBodies atomic.Bool
+
+// SetTraceBodies enables or disables logging of request and response
+// bodies in HTTP traces. Secret fields are redacted either way.
+func SetTraceBodies(enabled bool) {
+	traceBodies.Store(enabled)
+}
+
+// secretJSONKeys are JSON object keys whose values are never traced,
+// compared case-insensitively
+var secretJSONKeys = map[string]bool{
+	"secret":       true,
+	"token":        true,
+	"password":     true,
+	"code":         true,
+	"apikey":       true,
+	"sessiontoken": true,
+}
+
+// secretPathPrefixes are API paths whose next segment is a secret,
+// such as the device code in the device auth poll endpoint
+var secretPathPrefixes = []string{
+	"/auth/device-web-auth/poll/",
+}
+
+// traceTransport is an http.RoundTripper that logs each request it sends
+// through logger.Debug, with credentials and other secrets redacted
+type traceTransport struct {
+	// base is the transport used to send requests. If nil, the
+	// default transport is used.
+	base http.RoundTripper
+}
+
+// NewTraceTransport wraps the base transport so that requests are traced
+// when debug logging is enabled. If base is nil, the default transport
+// set through SetDefaultTransport is used.
+func NewTraceTransport(base http.RoundTripper) http.RoundTripper {
+	return &traceTransport{base: base}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = getDefaultTransport()
+	}
+
+	if !logger.IsDebug() {
+		return base.RoundTrip(req)
+	}
+
+	withBodies := traceBodies.Load()
+
+	logger.Debug("HTTP > %s %s", req.Method, redactURL(req.URL))
+	logHeaders("HTTP >", req.Header)
+	if withBodies && req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			logger.Debug("HTTP > %s", redactBody(data))
+		}
+	}
+
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	latency := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		logger.Debug("HTTP < %s %s failed after %v: %v", req.Method, redactURL(req.URL), latency, err)
+		return resp, err
+	}
+
+	logger.Debug("HTTP < %s %s %s (%v)", req.Method, redactURL(req.URL), resp.Status, latency)
+	logHeaders("HTTP <", resp.Header)
+	if withBodies && resp.Body != nil {
+		// Read the whole body so it can be handed back to the caller
+		// unchanged after it has been traced
+		data, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		if readErr != nil {
+			logger.Debug("HTTP < failed to read body: %v", readErr)
+		} else {
+			logger.Debug("HTTP < %s", redactBody(data))
+		}
+	}
+
+	return resp, nil
+}
+
+// logHeaders logs the given headers with credentials redacted
+func logHeaders(prefix string, header http.Header) {
+	for _, name := range slices.Sorted(maps.Keys(header)) {
+		for _, value := range header[name] {
+			logger.Debug("%s %s: %s", prefix, name, redactHeader(name, value))
+		}
+	}
+}
+
+// redactHeader returns the header value with credentials removed
+func redactHeader(name, value string) string {
+	switch http.CanonicalHeaderKey(name) {
+	case "Authorization", "Proxy-Authorization":
+		scheme, _, found := strings.Cut(value, " ")
+		if found {
+			return scheme + " " + redacted
+		}
+		return redacted
+	case "Cookie":
+		cookies := strings.Split(value, ";")
+		for i, cookie := range cookies {
+			cookieName, _, _ := strings.Cut(strings.TrimSpace(cookie), "=")
+			cookies[i] = cookieName + "=" + redacted
+		}
+		return strings.Join(cookies, "; ")
+	case "Set-Cookie":
+		cookieName, rest, _ := strings.Cut(value, "=")
+		_, attributes, found := strings.Cut(rest, ";")
+		if found {
+			return cookieName + "=" + redacted + ";" + attributes
+		}
+		return cookieName + "=" + redacted
+	default:
+		return value
+	}
+}
+
+// redactURL returns the URL as a string with secrets in the path,
+// query and user info removed
+func redactURL(u *url.URL) string {
+	redactedURL := *u
+
+	if redactedURL.User != nil {
+		redactedURL.User = url.User(redacted)
+	}
+
+	for _, prefix := range secretPathPrefixes {
+		_, after, found := strings.Cut(redactedURL.Path, prefix)
+		if found && after != "" {
+			redactedURL.Path = strings.TrimSuffix(redactedURL.Path, after) + redacted
+			redactedURL.RawPath = ""
+		}
+	}
+
+	if redactedURL.RawQuery != "" {
+		query := redactedURL.Query()
+		for key := range query {
+			if secretJSONKeys[strings.ToLower(key)] {
+				query.Set(key, redacted)
+			}
+		}
+		redactedURL.RawQuery = query.Encode()
+	}
+
+	// Keep the placeholder readable instead of percent-encoded
+	return strings.ReplaceAll(redactedURL.String(), url.PathEscape(redacted), redacted)
+}
+
+// redactBody returns a printable form of the body with secret fields
+// removed. Bodies that are not JSON are never printed, since their
+// contents cannot be inspected for secrets.
+func redactBody(data []byte) string {
+	if len(data) == 0 {
+		return "(empty body)"
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Sprintf("(%d bytes of non-JSON body omitted)", len(data))
+	}
+
+	out, err := json.Marshal(redactJSON(value))
+	if err != nil {
+		return fmt.Sprintf("(%d bytes of body omitted)", len(data))
+	}
+
+	if len(out) > maxTraceBodySize {
+		return string(out[:maxTraceBodySize]) + "... (truncated)"
+	}
+
+	return string(out)
+}
+
+// redactJSON replaces the values of secret keys in a decoded JSON value
+func redactJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if secretJSONKeys[strings.ToLower(key)] {
+				v[key] = redacted
+			} else {
+				v[key] = redactJSON(field)
+			}
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried by the client transport
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the first attempt.
+	// Zero disables retries entirely.
+	MaxRetries int
+	// InitialBackoff is the base delay before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts, including delays
+	// requested by the server through Retry-After
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+	}
+}
+
+var (
+	// defaultTransport is the base transport shared by all API clients so
+	// that connections are pooled across clients and requests
+	defaultTransport   http.RoundTripper = http.DefaultTransport.(*http.Transport).Clone()
+	defaultTransportMu sync.RWMutex
+)
+
+// SetDefaultTransport replaces the base transport used by all API clients
+// that were not created with an explicit transport, including clients
+// that already exist
+func SetDefaultTransport(transport http.RoundTripper) {
+	defaultTransportMu.Lock()
+	defer defaultTransportMu.Unlock()
+	defaultTransport = transport
+}
+
+// getDefaultTransport returns the current default base transport
+func getDefaultTransport() http.RoundTripper {
+	defaultTransportMu.RLock()
+	defer defaultTransportMu.RUnlock()
+	return defaultTransport
+}
+
+// retryTransport is an http.RoundTripper that retries failed requests
+// according to a RetryPolicy using exponential backoff with full jitter
+type retryTransport struct {
+	// base is the transport used for each attempt. If nil, the
+	// default transport is used.
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+// newRetryTransport wraps the base transport with the given retry policy
+func newRetryTransport(base http.RoundTripper, policy RetryPolicy) *retryTransport {
+	return &retryTransport{
+		base:   base,
+		policy: policy,
+	}
+}
+
+// baseTransport returns the transport used for each attempt
+func (t *retryTransport) baseTransport() http.RoundTripper {
+	if t.base != nil {
+		return t.base
+	}
+	return getDefaultTransport()
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.Body != nil {
+			// The body was consumed by the previous attempt, so it
+			// has to be recreated before the request can be resent.
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.baseTransport().RoundTrip(attemptReq)
+
+		if attempt >= t.policy.MaxRetries || !t.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = retryAfter
+				if t.policy.MaxBackoff > 0 {
+					delay = min(delay, t.policy.MaxBackoff)
+				}
+			}
+
+			// Drain and close the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+
+		if err := sleepContext(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// shouldRetry reports whether a request should be attempted again
+// given the outcome of the previous attempt
+func (t *retryTransport) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	// Never retry once the caller has given up
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	// A request whose body cannot be replayed can only be sent once
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		// Network errors are only safe to retry when the request is
+		// idempotent, since the server may have processed it already.
+		return isIdempotent(req.Method) && !errors.Is(err, context.Canceled)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		// Rate limited requests were rejected before being processed
+		return true
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		return isIdempotent(req.Method)
+	default:
+		return false
+	}
+}
+
+// backoff returns the delay before the given retry attempt using
+// exponential backoff with full jitter
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	if t.policy.InitialBackoff <= 0 {
+		return 0
+	}
+
+	ceiling := t.policy.InitialBackoff << attempt
+	if ceiling <= 0 || (t.policy.MaxBackoff > 0 && ceiling > t.policy.MaxBackoff) {
+		ceiling = t.policy.MaxBackoff
+	}
+
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+// isIdempotent reports whether requests with the given method can be
+// safely sent more than once
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter parses a Retry-After header value, which can be
+// either a number of seconds or an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// sleepContext waits for the given duration or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
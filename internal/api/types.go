@@ -2,9 +2,9 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
+	"net/http"
+	"slices"
 	"strconv"
-	"time"
 )
 
 // Client represents the API client configuration
@@ -15,18 +15,40 @@ type Client struct {
 	Token             string
 	SessionCookieName string
 	CSRFToken         string
-	HTTPClient        *HTTPClient
-}
-
-// HTTPClient wraps the standard http.Client with additional configuration
-type HTTPClient struct {
-	Timeout time.Duration
+	HTTPClient        *http.Client
 }
 
 // RequestOptions contains optional parameters for API requests
 type RequestOptions struct {
 	Headers map[string]string
 	Query   map[string]string
+	// ErrorMessages overrides the default error message for specific
+	// status codes when the server does not provide one
+	ErrorMessages map[int]string
+	// Response, if set, is populated with the raw HTTP response,
+	// including for requests that fail with an API error
+	Response *RawResponse
+}
+
+// RawResponse gives access to the underlying HTTP response of an API request
+type RawResponse struct {
+	StatusCode int
+	Header     http.Header
+	Cookies    []*http.Cookie
+	Body       []byte
+	// Message is the message field of the API response, if any
+	Message string
+}
+
+// Cookie returns the value of the first cookie matching one of the
+// given names, or an empty string if none was set in the response
+func (r *RawResponse) Cookie(names ...string) string {
+	for _, cookie := range r.Cookies {
+		if slices.Contains(names, cookie.Name) {
+			return cookie.Value
+		}
+	}
+	return ""
 }
 
 // FlexibleBool can unmarshal from both boolean and string JSON values
@@ -68,23 +90,6 @@ type APIResponse struct {
 	Stack   string          `json:"stack,omitempty"`
 }
 
-// ErrorResponse represents an API error response
-type ErrorResponse struct {
-	Message string `json:"message"`
-	Status  int    `json:"status"`
-	Stack   string `json:"stack,omitempty"`
-}
-
-// Error implements the error interface
-// Returns just the message if present, otherwise just the status code
-func (e *ErrorResponse) Error() string {
-	if e.Message != "" {
-		return e.Message
-	}
-	// If no message, return just the status code
-	return fmt.Sprintf("%d", e.Status)
-}
-
 // LoginRequest represents the request payload for login
 type LoginRequest struct {
 	Email        string `json:"email"`
@@ -119,12 +124,8 @@ type Org struct {
 
 // ListUserOrgsResponse represents the response from listing user organizations
 type ListUserOrgsResponse struct {
-	Orgs       []Org `json:"orgs"`
-	Pagination struct {
-		Total  int `json:"total"`
-		Limit  int `json:"limit"`
-		Offset int `json:"offset"`
-	} `json:"pagination"`
+	Orgs       []Org      `json:"orgs"`
+	Pagination Pagination `json:"pagination"`
 }
 
 // DeviceWebAuthStartRequest represents the request payload for starting device web auth
@@ -1,27 +1,79 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/fosrl/cli/internal/logger"
 	"github.com/spf13/viper"
 )
 
 type AccountStore struct {
 	// All operations must happen to the configuration file,
 	// so they must operate on separate Viper instances.
-	v *viper.Viper
+	v    *viper.Viper
+	file *configFile
+
+	// secrets holds the account secrets outside of the accounts
+	// file. It is nil when secrets are stored in plaintext.
+	secrets SecretStore
+	// secretsErr is set when the credential store holding the
+	// secrets could not be opened, such as the user's keyring
+	// when running as root
+	secretsErr error
+	// storedIDs are the accounts with secrets in the secret store
+	storedIDs map[string]bool
+	// envAccount is the account authenticating with the API key from
+	// APIKeyEnvVar. It takes the place of the active account and is
+	// never saved.
+	envAccount *Account
 
 	ActiveUserID string             `mapstructure:"activeUserId" json:"activeUserId"`
 	Accounts     map[string]Account `mapstructure:"accounts" json:"accounts"`
+	// CredentialStore is the credential store holding the secrets
+	// of the accounts. Empty means plaintext.
+	CredentialStore CredentialStore `mapstructure:"credentialStore" json:"credentialStore,omitempty"`
 }
 
+const (
+	// APIKeyEnvVar is the environment variable that provides an API key
+	// to authenticate with instead of the stored account credentials
+	APIKeyEnvVar = "PANGOLIN_API_KEY"
+	// HostEnvVar is the environment variable that provides the host of
+	// the API key from APIKeyEnvVar
+	HostEnvVar = "PANGOLIN_HOST"
+	// OrgEnvVar is the environment variable that provides the
+	// organization of the API key from APIKeyEnvVar
+	OrgEnvVar = "PANGOLIN_ORG"
+)
+
+// AccountType describes how an account authenticates with Pangolin
+type AccountType string
+
+const (
+	// AccountTypeSession accounts authenticate with a session token
+	// obtained through an interactive login. This is the default.
+	AccountTypeSession AccountType = "session"
+	// AccountTypeAPIKey accounts authenticate with an org or root API key
+	AccountTypeAPIKey AccountType = "apiKey"
+)
+
+// apiKeyAccountPrefix prefixes the account ID of API key accounts,
+// which are not associated with a user
+const apiKeyAccountPrefix = "apikey:"
+
 type Account struct {
 	UserID         string          `mapstructure:"userId" json:"userId"`
+	Type           AccountType     `mapstructure:"type" json:"type,omitempty"`
 	Host           string          `mapstructure:"host" json:"host"`
 	Email          string          `mapstructure:"email" json:"email"`
 	SessionToken   string          `mapstructure:"sessionToken" json:"sessionToken"`
+	APIKey         string          `mapstructure:"apiKey" json:"apiKey,omitempty"`
 	OrgID          string          `mapstructure:"orgId" json:"orgId,omitempty"`
 	OlmCredentials *OlmCredentials `mapstructure:"olmCredentials" json:"olmCredentials,omitempty"`
 }
@@ -31,48 +83,164 @@ type OlmCredentials struct {
 	Secret string `mapstructure:"secret" json:"secret"`
 }
 
-func newAccountViper() (*viper.Viper, error) {
+func newAccountViper() (*viper.Viper, *configFile, error) {
 	v := viper.New()
 
 	dir, err := GetPangolinConfigDir()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	accountsFile := filepath.Join(dir, "accounts.json")
 	v.SetConfigFile(accountsFile)
 	v.SetConfigType("json")
 
-	return v, nil
+	return v, &configFile{path: accountsFile, schema: accountsSchema}, nil
 }
 
-func LoadAccountStore() (*AccountStore, error) {
-	v, err := newAccountViper()
+// LoadAccountStore loads the accounts file and the account secrets from
+// the credential store they are kept in. Secrets are moved to the
+// credential store selected in the configuration if they are kept
+// elsewhere, including plaintext secrets from older versions.
+func LoadAccountStore(cfg *Config) (*AccountStore, error) {
+	v, file, err := newAccountViper()
 	if err != nil {
 		return nil, err
 	}
 
 	store := AccountStore{
 		v:            v,
+		file:         file,
 		ActiveUserID: "",
 		Accounts:     map[string]Account{},
 	}
 
-	if err := v.ReadInConfig(); err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return &store, nil
+	if err := file.read(v); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
 		}
+	} else if err := v.Unmarshal(&store); err != nil {
 		return nil, err
 	}
 
-	if err := v.Unmarshal(&store); err != nil {
-		return nil, err
+	current := store.CredentialStore
+	if current == "" {
+		current = CredentialStorePlaintext
+	}
+	store.CredentialStore = current
+
+	source, err := OpenSecretStore(current)
+	if err != nil {
+		// The accounts can still be used without their secrets,
+		// which is all that is needed when running as root.
+		logger.Debug("Failed to open %s credential store: %v", current, err)
+		store.secretsErr = fmt.Errorf("failed to open %s credential store: %w", current, err)
+		return &store, nil
+	}
+
+	if source != nil {
+		store.loadSecrets(source)
+	}
+	store.secrets = source
+
+	target := cfg.CredentialStore
+	if target == CredentialStoreAuto || target == "" {
+		// Keep secrets where they are, but move plaintext
+		// secrets to the keyring whenever it is available
+		target = current
+		if current == CredentialStorePlaintext {
+			target = CredentialStoreKeyring
+		}
+	}
+
+	if target != current {
+		store.migrateSecrets(target, cfg.CredentialStore != CredentialStoreAuto)
 	}
 
 	return &store, nil
 }
 
+// loadSecrets reads the secrets of all accounts from the secret store.
+// Secrets still present in the accounts file take precedence, so
+// that they are moved to the secret store on the next save.
+func (s *AccountStore) loadSecrets(source SecretStore) {
+	s.storedIDs = map[string]bool{}
+
+	for id, account := range s.Accounts {
+		for name, field := range accountSecrets(&account) {
+			if *field != "" {
+				continue
+			}
+
+			value, err := source.Get(secretKey(id, name))
+			if err != nil {
+				if !errors.Is(err, ErrSecretNotFound) {
+					logger.Debug("Failed to read %s of account %s: %v", name, id, err)
+				}
+				continue
+			}
+			*field = value
+		}
+
+		s.Accounts[id] = account
+		s.storedIDs[id] = true
+	}
+}
+
+// migrateSecrets moves all account secrets to the target credential
+// store. If the target cannot be used, the secrets are left in place,
+// with a warning if the target was explicitly configured.
+func (s *AccountStore) migrateSecrets(target CredentialStore, explicit bool) {
+	dest, err := OpenSecretStore(target)
+	if err != nil {
+		// Without an explicit choice, a missing keyring is expected
+		if explicit {
+			logger.Warning("Failed to open %s credential store, keeping credentials in %s: %v", target, s.CredentialStore, err)
+		}
+		return
+	}
+
+	source := s.secrets
+	sourceKind := s.CredentialStore
+	sourceIDs := s.storedIDs
+
+	s.secrets = dest
+	s.CredentialStore = target
+	s.storedIDs = nil
+
+	if len(s.Accounts) > 0 {
+		if err := s.Save(); err != nil {
+			logger.Warning("Failed to move credentials to %s credential store: %v", target, err)
+			s.secrets = source
+			s.CredentialStore = sourceKind
+			s.storedIDs = sourceIDs
+			return
+		}
+		logger.Debug("Moved credentials from %s to %s credential store", sourceKind, target)
+	}
+
+	if source != nil {
+		for id := range sourceIDs {
+			deleteAccountSecrets(source, id)
+		}
+	}
+}
+
+// deleteAccountSecrets removes all secrets of an account from the store
+func deleteAccountSecrets(store SecretStore, accountID string) {
+	for _, name := range secretNames {
+		if err := store.Delete(secretKey(accountID, name)); err != nil {
+			logger.Debug("Failed to delete %s of account %s: %v", name, accountID, err)
+		}
+	}
+}
+
 func (s *AccountStore) ActiveAccount() (*Account, error) {
+	if s.envAccount != nil {
+		envAccount := *s.envAccount
+		return &envAccount, nil
+	}
+
 	if s.ActiveUserID == "" {
 		return nil, errors.New("not logged in")
 	}
@@ -85,13 +253,133 @@ func (s *AccountStore) ActiveAccount() (*Account, error) {
 	return &activeAccount, nil
 }
 
+// UseEnvAccount makes the account the active account in place of the
+// stored one, without saving it. Client credentials stored for the same
+// API key are kept.
+func (s *AccountStore) UseEnvAccount(account Account) {
+	if existing, exists := s.Accounts[account.UserID]; exists && account.OlmCredentials == nil {
+		account.OlmCredentials = existing.OlmCredentials
+	}
+	s.envAccount = &account
+}
+
+// EnvAccount returns the account set through UseEnvAccount, or nil
+func (s *AccountStore) EnvAccount() *Account {
+	return s.envAccount
+}
+
+// SecretsError returns the error that prevented the account secrets from
+// being loaded, such as the user's keyring being unavailable to root
+func (s *AccountStore) SecretsError() error {
+	return s.secretsErr
+}
+
+// NewAPIKeyAccount creates an account that authenticates with an API key.
+// API keys are not tied to a user, so the account ID is derived from the
+// key ID, which is the part of the key before the first dot.
+func NewAPIKeyAccount(host string, apiKey string, orgID string) Account {
+	keyID, _, found := strings.Cut(apiKey, ".")
+	if !found {
+		sum := sha256.Sum256([]byte(apiKey))
+		keyID = hex.EncodeToString(sum[:])[:12]
+	}
+
+	return Account{
+		UserID: apiKeyAccountPrefix + keyID,
+		Type:   AccountTypeAPIKey,
+		Host:   host,
+		APIKey: apiKey,
+		OrgID:  orgID,
+	}
+}
+
+// IsAPIKey reports whether the account authenticates with an API key
+func (a *Account) IsAPIKey() bool {
+	return a.Type == AccountTypeAPIKey
+}
+
+// DisplayName returns a human-readable name for the account
+func (a *Account) DisplayName() string {
+	if a.IsAPIKey() {
+		return "API key " + strings.TrimPrefix(a.UserID, apiKeyAccountPrefix)
+	}
+	return a.Email
+}
+
 func (s *AccountStore) Save() error {
-	// HACK: If there's a better way to write the config all at once
-	// without having to specify each toplevel struct key, that
-	// would be preferable.
-	// However, this is fine for now.
-	s.v.Set("activeUserId", s.ActiveUserID)
-	s.v.Set("accounts", s.Accounts)
-
-	return s.v.WriteConfig()
+	accounts, err := s.storeSecrets()
+	if err != nil {
+		return err
+	}
+
+	return s.file.write(AccountStore{
+		ActiveUserID:    s.ActiveUserID,
+		Accounts:        accounts,
+		CredentialStore: s.CredentialStore,
+	})
+}
+
+// storeSecrets writes the account secrets to the secret store and
+// returns the accounts with their secrets removed, ready to be written
+// to the accounts file. Without a secret store, the accounts are
+// returned unchanged.
+func (s *AccountStore) storeSecrets() (map[string]Account, error) {
+	if s.secretsErr != nil {
+		// Secrets were never loaded, so the accounts are safe to
+		// write as long as no new secrets were added since.
+		for _, account := range s.Accounts {
+			for _, field := range accountSecrets(&account) {
+				if *field != "" {
+					return nil, s.secretsErr
+				}
+			}
+		}
+		return s.Accounts, nil
+	}
+
+	if s.secrets == nil {
+		return s.Accounts, nil
+	}
+
+	accounts := make(map[string]Account, len(s.Accounts))
+	storedIDs := make(map[string]bool, len(s.Accounts))
+
+	for id, account := range s.Accounts {
+		// Copy the credentials so the caller's account keeps its secret
+		if account.OlmCredentials != nil {
+			olmCredentials := *account.OlmCredentials
+			account.OlmCredentials = &olmCredentials
+		}
+
+		secrets := accountSecrets(&account)
+		for _, name := range secretNames {
+			key := secretKey(id, name)
+
+			field, ok := secrets[name]
+			if !ok || *field == "" {
+				if err := s.secrets.Delete(key); err != nil {
+					return nil, fmt.Errorf("failed to delete %s of account %s: %w", name, id, err)
+				}
+				continue
+			}
+
+			if err := s.secrets.Set(key, *field); err != nil {
+				return nil, fmt.Errorf("failed to store %s of account %s: %w", name, id, err)
+			}
+			*field = ""
+		}
+
+		accounts[id] = account
+		storedIDs[id] = true
+	}
+
+	// Remove the secrets of accounts that were logged out
+	for id := range s.storedIDs {
+		if !storedIDs[id] {
+			deleteAccountSecrets(s.secrets, id)
+		}
+	}
+	s.storedIDs = storedIDs
+
+	return accounts, nil
 }
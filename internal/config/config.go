@@ -9,55 +9,86 @@ import (
 	"strings"
 
 	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/olm"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
 	// All operations must happen to the configuration file,
 	// so they must operate on separate Viper instances.
-	v *viper.Viper
+	v    *viper.Viper
+	file *configFile
 
 	LogLevel           logger.LogLevel `mapstructure:"log_level" json:"log_level"`
 	LogFile            string          `mapstructure:"log_file" json:"log_file"`
+	SocketPath         string          `mapstructure:"socket_path" json:"socket_path"`
 	DisableUpdateCheck bool            `mapstructure:"disable_update_check" json:"disable_update_check"`
+	CredentialStore    CredentialStore `mapstructure:"credential_store" json:"credential_store"`
+
+	// Network settings for connections to Pangolin and the update checker
+	HTTPSProxy         string   `mapstructure:"https_proxy" json:"https_proxy"`
+	NoProxy            string   `mapstructure:"no_proxy" json:"no_proxy"`
+	CAFiles            []string `mapstructure:"ca_files" json:"ca_files"`
+	ClientCert         string   `mapstructure:"client_cert" json:"client_cert"`
+	ClientKey          string   `mapstructure:"client_key" json:"client_key"`
+	InsecureSkipVerify bool     `mapstructure:"insecure_skip_verify" json:"insecure_skip_verify"`
+
+	// Profiles are named sets of `up client` options
+	Profiles map[string]Profile `mapstructure:"profiles" json:"profiles,omitempty"`
+
+	// Hooks are commands run when the client connection comes up,
+	// goes down or switches organization
+	Hooks Hooks `mapstructure:"hooks" json:"hooks,omitzero"`
 }
 
-func newConfigViper() (*viper.Viper, error) {
+func newConfigViper() (*viper.Viper, *configFile, error) {
 	v := viper.New()
 
 	dir, err := GetPangolinConfigDir()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Bind to environment variables of the same name
-	v.SetEnvPrefix("PANGOLIN_CLI")
+	v.SetEnvPrefix(envPrefix)
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
-	configFile := filepath.Join(dir, "config.json")
-	v.SetConfigFile(configFile)
+	configPath := filepath.Join(dir, "config.json")
+	v.SetConfigFile(configPath)
 	v.SetConfigType("json")
 
+	setDefaults(v)
+
+	return v, &configFile{path: configPath, schema: configSchema}, nil
+}
+
+// setDefaults sets the default values of the configuration keys
+func setDefaults(v *viper.Viper) {
 	defaultLogPath := defaultLogPath()
 
-	// Defaults
 	v.SetDefault("log_level", "info")
 	v.SetDefault("log_file", defaultLogPath)
+	v.SetDefault("socket_path", olm.GetDefaultSocketPath())
 	v.SetDefault("disable_update_check", false)
-
-	return v, nil
+	v.SetDefault("credential_store", CredentialStoreAuto)
+	v.SetDefault("https_proxy", "")
+	v.SetDefault("no_proxy", "")
+	v.SetDefault("ca_files", []string{})
+	v.SetDefault("client_cert", "")
+	v.SetDefault("client_key", "")
+	v.SetDefault("insecure_skip_verify", false)
 }
 
 func LoadConfig() (*Config, error) {
-	v, err := newConfigViper()
+	v, file, err := newConfigViper()
 	if err != nil {
 		return nil, err
 	}
 
-	cfg := Config{v: v}
+	cfg := Config{v: v, file: file}
 
-	if err := v.ReadInConfig(); err != nil {
+	if err := file.read(v); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			if err := v.Unmarshal(&cfg); err != nil {
 				return nil, err
@@ -76,21 +107,38 @@ func LoadConfig() (*Config, error) {
 	return &cfg, nil
 }
 
+// ErrClientCertKeyPair is returned when only one of the client
+// certificate and key is configured
+var ErrClientCertKeyPair = errors.New("client_cert and client_key must be set together")
+
 func (c *Config) Validate() error {
 	switch c.LogLevel {
 	case logger.LogLevelDebug, logger.LogLevelInfo:
-		return nil
 	default:
 		return fmt.Errorf("invalid log level: %v", c.LogLevel)
 	}
+
+	if !validCredentialStore(c.CredentialStore) {
+		return fmt.Errorf("invalid credential store: %v", c.CredentialStore)
+	}
+
+	if (c.ClientCert == "") != (c.ClientKey == "") {
+		return ErrClientCertKeyPair
+	}
+
+	if c.SocketPath != "" && !filepath.IsAbs(c.SocketPath) {
+		return fmt.Errorf("invalid socket path %q: must be absolute", c.SocketPath)
+	}
+
+	if err := c.Hooks.validate(); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func (c *Config) Save() error {
-	c.v.Set("log_level", c.LogLevel)
-	c.v.Set("log_file", c.LogFile)
-	c.v.Set("disable_update_check", c.DisableUpdateCheck)
-
-	return c.v.WriteConfig()
+	return c.file.write(c)
 }
 
 // GetPangolinConfigDir returns the path to the .pangolin directory and ensures it exists
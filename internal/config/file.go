@@ -0,0 +1,304 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"strconv"
+
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/spf13/viper"
+)
+
+// configFile is a JSON file in the configuration directory that is
+// shared between processes, such as the CLI run by the user and the
+// detached client running as root.
+type configFile struct {
+	path   string
+	schema *schema
+	// base holds the settings as last read from or written to the
+	// file by this process
+	base map[string]any
+	// local holds the settings the copy of this process is based on,
+	// used to tell its changes apart from concurrent changes by other
+	// processes when merging
+	local map[string]any
+}
+
+// read loads the file into the viper instance and records its contents
+// as the base for later writes. Files from older versions are migrated
+// to the current schema, keeping a backup of the original. A missing
+// file is reported as os.ErrNotExist.
+func (f *configFile) read(v *viper.Viper) error {
+	unlock, err := lockFile(f.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		f.base = map[string]any{}
+		f.local = f.base
+		return err
+	}
+
+	settings, err := decodeSettings(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", f.path, err)
+	}
+
+	from, err := f.schema.migrate(f.path, settings)
+	if err != nil {
+		return err
+	}
+
+	if from != f.schema.version() {
+		data, err = f.upgrade(data, from, settings)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return err
+	}
+
+	f.base = settings
+	f.local = settings
+
+	return nil
+}
+
+// upgrade replaces the file with its migrated settings after backing up
+// the original contents, and returns the new contents
+func (f *configFile) upgrade(original []byte, from int, settings map[string]any) ([]byte, error) {
+	backupPath := fmt.Sprintf("%s.v%d.bak", f.path, from)
+	if err := writeFileAtomic(backupPath, original, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to back up %s: %w", f.path, err)
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeFileAtomic(f.path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write migrated %s: %w", f.path, err)
+	}
+
+	logger.Debug("Migrated %s from version %d to %d (backup: %s)", f.path, from, f.schema.version(), backupPath)
+
+	return data, nil
+}
+
+// write atomically replaces the file with the given settings, which
+// are marshaled to JSON. Changes made to the file by other processes
+// since it was read are kept, unless the same setting was also changed
+// by this process.
+func (f *configFile) write(settings any) error {
+	ours, err := normalizeSettings(settings)
+	if err != nil {
+		return err
+	}
+	ours[versionKey] = f.schema.version()
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+
+	unlock, err := lockFile(f.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	merged := ours
+	if data, err := os.ReadFile(f.path); err == nil {
+		theirs, err := decodeSettings(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", f.path, err)
+		}
+
+		version, err := fileVersion(theirs)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.path, err)
+		}
+		if err := f.schema.checkVersion(f.path, version); err != nil {
+			return err
+		}
+
+		merged = mergeSettings(f.local, ours, theirs)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(f.path, data, 0o600); err != nil {
+		return err
+	}
+
+	f.base = merged
+	f.local = ours
+
+	return nil
+}
+
+// writeFromBase writes settings that were derived from the settings as
+// last read from or written to the file, rather than from the copy of
+// this process
+func (f *configFile) writeFromBase(settings map[string]any) error {
+	f.local = f.base
+	return f.write(settings)
+}
+
+// decodeSettings parses a JSON settings file
+func decodeSettings(data []byte) (map[string]any, error) {
+	var settings map[string]any
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+
+	if settings == nil {
+		settings = map[string]any{}
+	}
+
+	return settings, nil
+}
+
+// normalizeSettings converts settings to their generic JSON form,
+// so they can be compared with settings decoded from a file
+func normalizeSettings(settings any) (map[string]any, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSettings(data)
+}
+
+// mergeSettings performs a three-way merge of settings changed by this
+// process (ours) and by another process (theirs) since both started
+// from base. Nested objects are merged key by key; for any other value,
+// our change wins and otherwise theirs is kept.
+func mergeSettings(base, ours, theirs map[string]any) map[string]any {
+	merged := map[string]any{}
+
+	keys := map[string]bool{}
+	for key := range ours {
+		keys[key] = true
+	}
+	for key := range theirs {
+		keys[key] = true
+	}
+
+	for key := range keys {
+		baseValue, inBase := base[key]
+		ourValue, inOurs := ours[key]
+		theirValue, inTheirs := theirs[key]
+
+		ourMap, oursIsMap := ourValue.(map[string]any)
+		theirMap, theirsIsMap := theirValue.(map[string]any)
+		if oursIsMap && theirsIsMap {
+			baseMap, _ := baseValue.(map[string]any)
+			merged[key] = mergeSettings(baseMap, ourMap, theirMap)
+			continue
+		}
+
+		unchanged := inOurs == inBase && reflect.DeepEqual(ourValue, baseValue)
+		switch {
+		case unchanged && inTheirs:
+			merged[key] = theirValue
+		case unchanged:
+			// Removed by the other process
+		case inOurs:
+			merged[key] = ourValue
+		}
+	}
+
+	return merged
+}
+
+// writeFileAtomic writes data to a temporary file that is synced to disk
+// and then renamed over the destination, so readers never observe a
+// partially written file. When running through sudo, the file is owned
+// by the invoking user, so it stays usable without sudo.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := chownToSudoUser(tmpPath); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	// Sync the directory so the rename itself is durable
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+
+	return nil
+}
+
+// chownToSudoUser gives ownership of the path to the user who invoked
+// sudo, if running as root through sudo
+func chownToSudoUser(path string) error {
+	if os.Geteuid() != 0 {
+		return nil
+	}
+
+	sudoUser := os.Getenv("SUDO_USER")
+	if sudoUser == "" {
+		return nil
+	}
+
+	u, err := user.Lookup(sudoUser)
+	if err != nil {
+		return fmt.Errorf("failed to lookup original user %s: %w", sudoUser, err)
+	}
+
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return nil
+	}
+	gid, err := strconv.Atoi(u.Gid)
+	if err != nil {
+		return nil
+	}
+
+	return os.Chown(path, uid, gid)
+}
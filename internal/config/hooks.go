@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultHookTimeout is how long a hook may run when no timeout is
+// configured
+const DefaultHookTimeout = 30 * time.Second
+
+// HookCommands are shell commands run on connection events, in order
+type HookCommands struct {
+	PreUp         []string `mapstructure:"pre_up" json:"pre_up,omitempty"`
+	PostUp        []string `mapstructure:"post_up" json:"post_up,omitempty"`
+	PreDown       []string `mapstructure:"pre_down" json:"pre_down,omitempty"`
+	PostDown      []string `mapstructure:"post_down" json:"post_down,omitempty"`
+	PostOrgSwitch []string `mapstructure:"post_org_switch" json:"post_org_switch,omitempty"`
+}
+
+// Hooks configures the commands run on connection events. Hooks of the
+// organization of the connection run after the global ones.
+type Hooks struct {
+	HookCommands `mapstructure:",squash"`
+
+	// Timeout is the maximum run time of each command, such as 30s
+	Timeout string `mapstructure:"timeout" json:"timeout,omitempty"`
+	// Orgs holds the hooks run only for connections to an organization,
+	// keyed by organization ID, which is matched case-insensitively
+	Orgs map[string]HookCommands `mapstructure:"orgs" json:"orgs,omitempty"`
+}
+
+// TimeoutDuration returns the maximum run time of each command
+func (h Hooks) TimeoutDuration() time.Duration {
+	timeout, err := time.ParseDuration(h.Timeout)
+	if err != nil || timeout <= 0 {
+		return DefaultHookTimeout
+	}
+	return timeout
+}
+
+func (h Hooks) validate() error {
+	if h.Timeout == "" {
+		return nil
+	}
+
+	timeout, err := time.ParseDuration(h.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid hooks timeout: %w", err)
+	}
+	if timeout <= 0 {
+		return fmt.Errorf("invalid hooks timeout: must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/fosrl/cli/internal/olm"
+)
+
+// Instance is a client tunnel that can run alongside others, each with
+// its own control socket, interface and log file. The default instance
+// has an empty name and uses the configured socket and log file.
+type Instance struct {
+	Name          string
+	SocketPath    string
+	InterfaceName string
+	LogFile       string
+}
+
+var instanceNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+const (
+	maxInstanceNameLength = 32
+	// instanceInterfacePrefix prefixes the interface names of named
+	// instances
+	instanceInterfacePrefix = "pg-"
+	// maxInterfaceNameLength is the longest interface name Linux allows
+	maxInterfaceNameLength = 15
+)
+
+// DisplayName returns the name of the instance for display
+func (i Instance) DisplayName() string {
+	if i.Name == "" {
+		return "(default)"
+	}
+	return i.Name
+}
+
+// Instance returns the instance with the given name. The socket and log
+// file of a named instance are placed next to those of the default
+// instance, with the name appended. Its interface is named after it.
+func (c *Config) Instance(name string) (Instance, error) {
+	if name == "" {
+		return Instance{
+			SocketPath:    c.SocketPath,
+			InterfaceName: olm.DefaultInterfaceName,
+			LogFile:       c.LogFile,
+		}, nil
+	}
+
+	if err := ValidateInstanceName(name); err != nil {
+		return Instance{}, err
+	}
+
+	return Instance{
+		Name:          name,
+		SocketPath:    withNameSuffix(c.SocketPath, name),
+		InterfaceName: instanceInterfaceName(name),
+		LogFile:       withNameSuffix(c.LogFile, name),
+	}, nil
+}
+
+// ValidateInstanceName checks that the name can be used for a named
+// instance
+func ValidateInstanceName(name string) error {
+	if !instanceNameRegexp.MatchString(name) || len(name) > maxInstanceNameLength {
+		return fmt.Errorf("invalid instance name %q: must be at most %d lowercase letters, digits and dashes", name, maxInstanceNameLength)
+	}
+	return nil
+}
+
+// Instances returns the default instance followed by the named instances
+// whose control socket exists, sorted by name
+func (c *Config) Instances() ([]Instance, error) {
+	defaultInstance, _ := c.Instance("")
+	instances := []Instance{defaultInstance}
+
+	ext := filepath.Ext(c.SocketPath)
+	prefix := strings.TrimSuffix(filepath.Base(c.SocketPath), ext) + "-"
+
+	entries, err := os.ReadDir(filepath.Dir(c.SocketPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return instances, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok || entry.Type()&os.ModeSocket == 0 {
+			continue
+		}
+		if name, ok = strings.CutSuffix(name, ext); ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		instance, err := c.Instance(name)
+		if err != nil {
+			// Not a socket of an instance
+			continue
+		}
+		instances = append(instances, instance)
+	}
+
+	return instances, nil
+}
+
+// instanceInterfaceName returns the interface name of the named instance.
+// Names too long for an interface name are shortened, with a hash of the
+// full name appended to keep them apart.
+func instanceInterfaceName(name string) string {
+	interfaceName := instanceInterfacePrefix + name
+	if len(interfaceName) <= maxInterfaceNameLength {
+		return interfaceName
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	suffix := "-" + hex.EncodeToString(sum[:])[:4]
+	return strings.TrimRight(interfaceName[:maxInterfaceNameLength-len(suffix)], "-") + suffix
+}
+
+// withNameSuffix appends the name to the file name of the path, before
+// its extension
+func withNameSuffix(path string, name string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + name + ext
+}
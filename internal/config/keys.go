@@ -0,0 +1,343 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/spf13/viper"
+)
+
+// envPrefix is the prefix of environment variables that override
+// configuration values
+const envPrefix = "PANGOLIN_CLI"
+
+// KeyType is the type of value a configuration key holds
+type KeyType string
+
+const (
+	KeyTypeString KeyType = "string"
+	KeyTypeBool   KeyType = "bool"
+	KeyTypeList   KeyType = "list"
+)
+
+// ValueSource describes where the effective value of a key came from
+type ValueSource string
+
+const (
+	ValueSourceDefault ValueSource = "default"
+	ValueSourceFile    ValueSource = "file"
+	ValueSourceEnv     ValueSource = "env"
+)
+
+// Key describes a configuration key that can be changed by the user
+type Key struct {
+	Name        string
+	Description string
+	Type        KeyType
+	// Allowed lists the valid values of a string key, if restricted
+	Allowed []string
+	// validate checks a parsed value beyond its type, if set
+	validate func(value any) error
+}
+
+var keys = []Key{
+	{
+		Name:        "log_level",
+		Description: "Log level for CLI output",
+		Type:        KeyTypeString,
+		Allowed:     []string{string(logger.LogLevelDebug), string(logger.LogLevelInfo)},
+	},
+	{
+		Name:        "log_file",
+		Description: "Path of the client log file",
+		Type:        KeyTypeString,
+	},
+	{
+		Name:        "socket_path",
+		Description: "Path of the client control socket",
+		Type:        KeyTypeString,
+		validate:    validateAbsolutePath,
+	},
+	{
+		Name:        "disable_update_check",
+		Description: "Do not check for new versions",
+		Type:        KeyTypeBool,
+	},
+	{
+		Name:        "credential_store",
+		Description: "Where account secrets are stored",
+		Type:        KeyTypeString,
+		Allowed: []string{
+			string(CredentialStoreAuto),
+			string(CredentialStoreKeyring),
+			string(CredentialStoreFile),
+			string(CredentialStorePlaintext),
+		},
+	},
+	{
+		Name:        "https_proxy",
+		Description: "Proxy URL for HTTPS requests",
+		Type:        KeyTypeString,
+		validate:    validateProxyURL,
+	},
+	{
+		Name:        "no_proxy",
+		Description: "Comma-separated hosts that bypass the proxy",
+		Type:        KeyTypeString,
+	},
+	{
+		Name:        "ca_files",
+		Description: "Additional trusted CA certificate files",
+		Type:        KeyTypeList,
+		validate:    validateFilesExist,
+	},
+	{
+		Name:        "client_cert",
+		Description: "Client certificate file for mutual TLS",
+		Type:        KeyTypeString,
+		validate:    validateFilesExist,
+	},
+	{
+		Name:        "client_key",
+		Description: "Client private key file for mutual TLS",
+		Type:        KeyTypeString,
+		validate:    validateFilesExist,
+	},
+	{
+		Name:        "insecure_skip_verify",
+		Description: "Skip TLS certificate verification",
+		Type:        KeyTypeBool,
+	},
+}
+
+// Keys returns all configuration keys that can be changed by the user
+func Keys() []Key {
+	return slices.Clone(keys)
+}
+
+// KeyNames returns the names of all configuration keys
+func KeyNames() []string {
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.Name
+	}
+	return names
+}
+
+// LookupKey returns the configuration key with the given name
+func LookupKey(name string) (Key, error) {
+	for _, key := range keys {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+	return Key{}, fmt.Errorf("unknown configuration key %q", name)
+}
+
+// EnvVar returns the environment variable that overrides the key
+func (k Key) EnvVar() string {
+	return envPrefix + "_" + strings.ToUpper(k.Name)
+}
+
+// Parse converts a value given on the command line to the key's type
+// and validates it
+func (k Key) Parse(raw string) (any, error) {
+	var value any
+
+	switch k.Type {
+	case KeyTypeBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", k.Name)
+		}
+		value = b
+	case KeyTypeList:
+		items := []string{}
+		for item := range strings.SplitSeq(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value = items
+	default:
+		if len(k.Allowed) > 0 && !slices.Contains(k.Allowed, raw) {
+			return nil, fmt.Errorf("%s must be one of: %s", k.Name, strings.Join(k.Allowed, ", "))
+		}
+		value = raw
+	}
+
+	if k.validate != nil {
+		if err := k.validate(value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", k.Name, err)
+		}
+	}
+
+	return value, nil
+}
+
+// Path returns the path of the configuration file
+func (c *Config) Path() string {
+	return c.file.path
+}
+
+// Value returns the effective value of the key and where it came from
+func (c *Config) Value(name string) (any, ValueSource, error) {
+	key, err := LookupKey(name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	source := ValueSourceDefault
+	if os.Getenv(key.EnvVar()) != "" {
+		source = ValueSourceEnv
+	} else if _, ok := c.file.base[key.Name]; ok {
+		source = ValueSourceFile
+	}
+
+	value := c.v.Get(key.Name)
+	if key.Type == KeyTypeList {
+		value = c.v.GetStringSlice(key.Name)
+	}
+
+	return value, source, nil
+}
+
+// SetValues parses and validates the values, keyed by key name, and
+// saves them to the configuration file together, leaving all other keys
+// as they are in the file. Keys that must be set together, such as
+// client_cert and client_key, are validated as a whole.
+func (c *Config) SetValues(raws map[string]string) error {
+	settings := maps.Clone(c.file.base)
+	if settings == nil {
+		settings = map[string]any{}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(raws)) {
+		key, err := LookupKey(name)
+		if err != nil {
+			return err
+		}
+
+		value, err := key.Parse(raws[name])
+		if err != nil {
+			return err
+		}
+		settings[key.Name] = value
+	}
+
+	return c.saveSettings(settings)
+}
+
+// UnsetValues removes the keys from the configuration file together, so
+// that their default values apply again
+func (c *Config) UnsetValues(names ...string) error {
+	settings := maps.Clone(c.file.base)
+
+	for _, name := range names {
+		key, err := LookupKey(name)
+		if err != nil {
+			return err
+		}
+		delete(settings, key.Name)
+	}
+
+	return c.saveSettings(settings)
+}
+
+// CreateFile creates the configuration file with the default value of
+// every key. Values from the environment are not written to it.
+func (c *Config) CreateFile() error {
+	defaults := viper.New()
+	setDefaults(defaults)
+
+	settings := map[string]any{}
+	for _, key := range keys {
+		settings[key.Name] = defaults.Get(key.Name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.file.path), 0o755); err != nil {
+		return err
+	}
+
+	return c.file.writeFromBase(settings)
+}
+
+// saveSettings validates the configuration that results from the given
+// file contents and writes them to the configuration file
+func (c *Config) saveSettings(settings map[string]any) error {
+	v, _, err := newConfigViper()
+	if err != nil {
+		return err
+	}
+
+	if err := v.MergeConfigMap(settings); err != nil {
+		return err
+	}
+
+	var candidate Config
+	if err := v.Unmarshal(&candidate); err != nil {
+		return err
+	}
+
+	if err := candidate.Validate(); err != nil {
+		return err
+	}
+
+	return c.file.writeFromBase(settings)
+}
+
+// validateProxyURL checks that a proxy URL is absolute, if set
+func validateProxyURL(value any) error {
+	raw, _ := value.(string)
+	if raw == "" {
+		return nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return errors.New("proxy URL must include a scheme and host, such as http://proxy:3128")
+	}
+
+	return nil
+}
+
+// validateAbsolutePath checks that the path is absolute
+func validateAbsolutePath(value any) error {
+	path, _ := value.(string)
+	if path != "" && !filepath.IsAbs(path) {
+		return errors.New("path must be absolute")
+	}
+	return nil
+}
+
+// validateFilesExist checks that the file or files named by the value exist
+func validateFilesExist(value any) error {
+	var paths []string
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			paths = []string{v}
+		}
+	case []string:
+		paths = v
+	}
+
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
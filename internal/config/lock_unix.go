@@ -0,0 +1,39 @@
+//go:build !windows
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive lock shared by all processes that access
+// the file at path, blocking until it is available. The lock is held on
+// a separate lock file, since the file itself is replaced on write.
+func lockFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// The configuration directory does not exist yet, so
+			// there is nothing to protect
+			return func() {}, nil
+		}
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	// A lock file created by root must stay usable by the user
+	_ = chownToSudoUser(lockPath)
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
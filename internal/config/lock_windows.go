@@ -0,0 +1,9 @@
+//go:build windows
+
+package config
+
+// lockFile is a no-op on Windows, where the client does not run as a
+// separate elevated process that could write concurrently.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Profile is a named set of `up client` options. Keys are profile key
+// names, which correspond to `up client` flags with underscores in
+// place of dashes.
+type Profile map[string]any
+
+// ProfileKey describes an option that can be stored in a profile
+type ProfileKey struct {
+	Name        string
+	Description string
+	Type        ProfileKeyType
+}
+
+// ProfileKeyType is the type of value a profile key holds
+type ProfileKeyType string
+
+const (
+	ProfileKeyTypeString   ProfileKeyType = "string"
+	ProfileKeyTypeInt      ProfileKeyType = "int"
+	ProfileKeyTypeBool     ProfileKeyType = "bool"
+	ProfileKeyTypeDuration ProfileKeyType = "duration"
+	ProfileKeyTypeList     ProfileKeyType = "list"
+)
+
+var profileKeys = []ProfileKey{
+	{Name: "org", Description: "Organization ID", Type: ProfileKeyTypeString},
+	{Name: "endpoint", Description: "Client endpoint", Type: ProfileKeyTypeString},
+	{Name: "mtu", Description: "Maximum transmission unit", Type: ProfileKeyTypeInt},
+	{Name: "netstack_dns", Description: "DNS server to use for Netstack", Type: ProfileKeyTypeString},
+	{Name: "interface_name", Description: "Interface name", Type: ProfileKeyTypeString},
+	{Name: "log_level", Description: "Log level", Type: ProfileKeyTypeString},
+	{Name: "http_addr", Description: "HTTP address for API server", Type: ProfileKeyTypeString},
+	{Name: "ping_interval", Description: "Ping interval", Type: ProfileKeyTypeDuration},
+	{Name: "ping_timeout", Description: "Ping timeout", Type: ProfileKeyTypeDuration},
+	{Name: "holepunch", Description: "Enable holepunching", Type: ProfileKeyTypeBool},
+	{Name: "tls_client_cert", Description: "TLS client certificate path", Type: ProfileKeyTypeString},
+	{Name: "override_dns", Description: "Override system DNS for resolving internal resource alias", Type: ProfileKeyTypeBool},
+	{Name: "tunnel_dns", Description: "Use tunnel DNS for internal resource alias resolution", Type: ProfileKeyTypeBool},
+	{Name: "upstream_dns", Description: "DNS servers to use for external DNS resolution", Type: ProfileKeyTypeList},
+}
+
+var profileNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ProfileKeys returns all keys that can be stored in a profile
+func ProfileKeys() []ProfileKey {
+	return slices.Clone(profileKeys)
+}
+
+// LookupProfileKey returns the profile key with the given name
+func LookupProfileKey(name string) (ProfileKey, error) {
+	for _, key := range profileKeys {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+	return ProfileKey{}, fmt.Errorf("unknown profile key %q", name)
+}
+
+// FlagName returns the name of the `up client` flag set by the key
+func (k ProfileKey) FlagName() string {
+	return strings.ReplaceAll(k.Name, "_", "-")
+}
+
+// Parse converts a value given on the command line to the key's type
+func (k ProfileKey) Parse(raw string) (any, error) {
+	switch k.Type {
+	case ProfileKeyTypeInt:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", k.Name)
+		}
+		return n, nil
+	case ProfileKeyTypeBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", k.Name)
+		}
+		return b, nil
+	case ProfileKeyTypeDuration:
+		if _, err := time.ParseDuration(raw); err != nil {
+			return nil, fmt.Errorf("%s must be a duration such as 5s", k.Name)
+		}
+		return raw, nil
+	case ProfileKeyTypeList:
+		items := []string{}
+		for item := range strings.SplitSeq(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	default:
+		return raw, nil
+	}
+}
+
+// FormatProfileValue formats a profile value as it would be given
+// to the corresponding flag on the command line
+func FormatProfileValue(value any) string {
+	switch v := value.(type) {
+	case []string:
+		return strings.Join(v, ",")
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ValidateProfileName checks that a profile name can be stored in the
+// configuration file, where keys are case-insensitive
+func ValidateProfileName(name string) error {
+	if !profileNameRegexp.MatchString(name) {
+		return errors.New("profile names must start with a lowercase letter or digit and contain only lowercase letters, digits, dashes and underscores")
+	}
+	return nil
+}
+
+// Profile returns the profile with the given name
+func (c *Config) Profile(name string) (Profile, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found", name)
+	}
+	return profile, nil
+}
+
+// SaveProfile stores the profile under the given name in the
+// configuration file, replacing any existing profile of that name
+func (c *Config) SaveProfile(name string, profile Profile) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+
+	for key := range profile {
+		if _, err := LookupProfileKey(key); err != nil {
+			return err
+		}
+	}
+
+	settings := c.settingsWithProfiles(func(profiles map[string]any) {
+		profiles[name] = map[string]any(maps.Clone(profile))
+	})
+
+	if err := c.saveSettings(settings); err != nil {
+		return err
+	}
+
+	if c.Profiles == nil {
+		c.Profiles = map[string]Profile{}
+	}
+	c.Profiles[name] = profile
+
+	return nil
+}
+
+// DeleteProfile removes the profile with the given name from the
+// configuration file
+func (c *Config) DeleteProfile(name string) error {
+	if _, err := c.Profile(name); err != nil {
+		return err
+	}
+
+	settings := c.settingsWithProfiles(func(profiles map[string]any) {
+		delete(profiles, name)
+	})
+
+	if err := c.saveSettings(settings); err != nil {
+		return err
+	}
+
+	delete(c.Profiles, name)
+
+	return nil
+}
+
+// settingsWithProfiles returns a copy of the configuration file contents
+// with the profiles changed by the given function
+func (c *Config) settingsWithProfiles(change func(profiles map[string]any)) map[string]any {
+	settings := maps.Clone(c.file.base)
+	if settings == nil {
+		settings = map[string]any{}
+	}
+
+	profiles := map[string]any{}
+	if existing, ok := settings["profiles"].(map[string]any); ok {
+		profiles = maps.Clone(existing)
+	}
+
+	change(profiles)
+
+	if len(profiles) == 0 {
+		delete(settings, "profiles")
+	} else {
+		settings["profiles"] = profiles
+	}
+
+	return settings
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/viper"
+)
+
+// ProjectFileName is the name of the project file that declares the
+// connection a project directory needs
+const ProjectFileName = ".pangolin.yaml"
+
+// ProjectConfig is the connection declared by a project file, such as:
+//
+//	host: https://pangolin.example.com
+//	org: my-org
+//	tunnel:
+//	  tunnel_dns: true
+//	resources:
+//	  - database
+type ProjectConfig struct {
+	// path is the project file the configuration was read from
+	path string
+
+	// Host is the Pangolin host the project's organization is on
+	Host string `mapstructure:"host"`
+	// Org is the organization to connect to
+	Org string `mapstructure:"org"`
+	// Tunnel holds `up client` options, using the same keys as
+	// profiles, except for the keys in untrustedProjectKeys
+	Tunnel Profile `mapstructure:"tunnel"`
+	// Resources are the names of the sites that must be connected for
+	// the project's resources to be reachable
+	Resources []string `mapstructure:"resources"`
+}
+
+// FindProjectConfig looks for a project file in the given directory and
+// its parents, and loads the closest one. It returns nil without an
+// error when there is no project file.
+func FindProjectConfig(dir string) (*ProjectConfig, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return LoadProjectConfig(path)
+		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// LoadProjectConfig loads and validates the project file at the given path
+func LoadProjectConfig(path string) (*ProjectConfig, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	project := ProjectConfig{path: path}
+	if err := v.Unmarshal(&project); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if err := project.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &project, nil
+}
+
+// Path returns the path of the project file
+func (p *ProjectConfig) Path() string {
+	return p.path
+}
+
+// untrustedProjectKeys are the tunnel options a project file must not
+// set. Project files are picked up from any parent directory, such as a
+// cloned repository, so they must not be able to send the credentials of
+// the client to another server, expose its API or choose its certificate.
+var untrustedProjectKeys = []string{"endpoint", "http_addr", "tls_client_cert"}
+
+func (p *ProjectConfig) validate() error {
+	for keyName := range p.Tunnel {
+		key, err := LookupProfileKey(keyName)
+		if err != nil {
+			return fmt.Errorf("tunnel: %w", err)
+		}
+
+		if key.Name == "org" {
+			return errors.New("tunnel: org must be set at the top level")
+		}
+
+		if slices.Contains(untrustedProjectKeys, key.Name) {
+			return fmt.Errorf("tunnel: %s cannot be set in a project file, pass --%s instead", key.Name, key.FlagName())
+		}
+
+		if _, err := key.Parse(FormatProfileValue(p.Tunnel[keyName])); err != nil {
+			return fmt.Errorf("tunnel: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// versionKey is the key holding the schema version in every
+// configuration file. Files without it are version 0.
+const versionKey = "version"
+
+// migration reshapes the contents of a configuration file from
+// one schema version to the next
+type migration struct {
+	// description explains what the migration changes
+	description string
+	apply       func(settings map[string]any) error
+}
+
+// schema describes the versions of a configuration file and how to
+// migrate older files to the current version
+type schema struct {
+	name string
+	// migrations[i] migrates a file from version i to version i+1,
+	// so the current version is the number of migrations
+	migrations []migration
+}
+
+// version returns the current schema version
+func (s *schema) version() int {
+	return len(s.migrations)
+}
+
+// fileVersion returns the schema version of the file contents
+func fileVersion(settings map[string]any) (int, error) {
+	value, ok := settings[versionKey]
+	if !ok {
+		return 0, nil
+	}
+
+	version, ok := value.(float64)
+	if !ok || version < 0 || version != float64(int(version)) {
+		return 0, fmt.Errorf("invalid %s: %v", versionKey, value)
+	}
+
+	return int(version), nil
+}
+
+// checkVersion returns an error if the file was written by a newer
+// version of the CLI, which this version cannot safely read or write
+func (s *schema) checkVersion(path string, version int) error {
+	if version > s.version() {
+		return fmt.Errorf("%s was written by a newer version of Pangolin CLI (%s schema version %d, this version supports up to %d); please upgrade Pangolin CLI", path, s.name, version, s.version())
+	}
+	return nil
+}
+
+// migrate applies all migrations needed to bring the settings to the
+// current version in place, and returns the version they started at
+func (s *schema) migrate(path string, settings map[string]any) (int, error) {
+	from, err := fileVersion(settings)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := s.checkVersion(path, from); err != nil {
+		return 0, err
+	}
+
+	for version := from; version < s.version(); version++ {
+		if err := s.migrations[version].apply(settings); err != nil {
+			return 0, fmt.Errorf("failed to migrate %s to version %d (%s): %w", path, version+1, s.migrations[version].description, err)
+		}
+	}
+
+	settings[versionKey] = s.version()
+
+	return from, nil
+}
+
+var configSchema = &schema{
+	name: "config",
+	migrations: []migration{
+		{
+			description: "add schema version",
+			apply:       func(settings map[string]any) error { return nil },
+		},
+	},
+}
+
+var accountsSchema = &schema{
+	name: "accounts",
+	migrations: []migration{
+		{
+			description: "use canonical key names",
+			apply:       canonicalizeAccountsKeys,
+		},
+	},
+}
+
+// canonicalizeAccountsKeys renames top-level keys that older versions
+// wrote in lowercase to the names used by AccountStore
+func canonicalizeAccountsKeys(settings map[string]any) error {
+	for _, key := range []string{"activeUserId", "accounts", "credentialStore"} {
+		for existing, value := range settings {
+			if existing != key && strings.EqualFold(existing, key) {
+				delete(settings, existing)
+				settings[key] = value
+			}
+		}
+	}
+	return nil
+}
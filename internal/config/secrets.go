@@ -0,0 +1,105 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CredentialStore selects where account secrets such as session tokens,
+// API keys and client secrets are stored
+type CredentialStore string
+
+const (
+	// CredentialStoreAuto uses the backend the secrets are already stored
+	// in, or the system keyring if available for new and plaintext secrets
+	CredentialStoreAuto CredentialStore = "auto"
+	// CredentialStoreKeyring stores secrets in the system keyring
+	// through the Secret Service API
+	CredentialStoreKeyring CredentialStore = "keyring"
+	// CredentialStoreFile stores secrets in a file encrypted with
+	// the passphrase from SecretsPassphraseEnvVar
+	CredentialStoreFile CredentialStore = "file"
+	// CredentialStorePlaintext stores secrets in the accounts file
+	CredentialStorePlaintext CredentialStore = "plaintext"
+)
+
+// SecretsPassphraseEnvVar is the environment variable that provides the
+// passphrase for the encrypted file credential store
+const SecretsPassphraseEnvVar = "PANGOLIN_SECRETS_PASSPHRASE"
+
+// ErrSecretNotFound is returned by a SecretStore when no secret
+// is stored under the requested key
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretStore stores secret values outside of the accounts file
+type SecretStore interface {
+	// Get returns the secret stored under the key, or
+	// ErrSecretNotFound if there is none
+	Get(key string) (string, error)
+	// Set stores the secret under the key, replacing any existing value
+	Set(key string, value string) error
+	// Delete removes the secret stored under the key, if any
+	Delete(key string) error
+}
+
+// OpenSecretStore opens the backend for the given credential store.
+// The plaintext store has no backend, so nil is returned for it.
+func OpenSecretStore(kind CredentialStore) (SecretStore, error) {
+	switch kind {
+	case CredentialStorePlaintext, "":
+		return nil, nil
+	case CredentialStoreKeyring:
+		store, err := openKeyringSecretStore()
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case CredentialStoreFile:
+		store, err := openFileSecretStore()
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("invalid credential store: %v", kind)
+	}
+}
+
+// validCredentialStore reports whether kind is a supported credential store
+func validCredentialStore(kind CredentialStore) bool {
+	switch kind {
+	case CredentialStoreAuto, CredentialStoreKeyring, CredentialStoreFile, CredentialStorePlaintext:
+		return true
+	default:
+		return false
+	}
+}
+
+// Names of the secret fields of an account, used to build secret keys
+const (
+	secretSessionToken = "sessionToken"
+	secretAPIKey       = "apiKey"
+	secretOlmSecret    = "olmSecret"
+)
+
+var secretNames = []string{secretSessionToken, secretAPIKey, secretOlmSecret}
+
+// secretKey returns the key under which a secret of an account is stored
+func secretKey(accountID string, name string) string {
+	return accountID + "/" + name
+}
+
+// accountSecrets returns pointers to the secret fields of an account,
+// keyed by secret name
+func accountSecrets(account *Account) map[string]*string {
+	secrets := map[string]*string{
+		secretSessionToken: &account.SessionToken,
+		secretAPIKey:       &account.APIKey,
+	}
+
+	if account.OlmCredentials != nil {
+		secrets[secretOlmSecret] = &account.OlmCredentials.Secret
+	}
+
+	return secrets
+}
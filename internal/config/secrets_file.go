@@ -0,0 +1,243 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Parameters for deriving the encryption key from the passphrase
+const (
+	scryptN       = 1 << 15
+	scryptR       = 8
+	scryptP       = 1
+	scryptKeySize = 32
+	saltSize      = 16
+)
+
+// encryptedSecretsFile is the on-disk format of the encrypted file store
+type encryptedSecretsFile struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// fileSecretStore stores secrets in a single file encrypted with
+// AES-GCM, using a key derived from a passphrase with scrypt
+type fileSecretStore struct {
+	path       string
+	passphrase string
+
+	// salt and key are derived once and reused for every write
+	salt []byte
+	key  []byte
+
+	secrets map[string]string
+}
+
+func openFileSecretStore() (*fileSecretStore, error) {
+	passphrase := os.Getenv(SecretsPassphraseEnvVar)
+	if passphrase == "" {
+		return nil, fmt.Errorf("%s must be set to use the encrypted file credential store", SecretsPassphraseEnvVar)
+	}
+
+	dir, err := GetPangolinConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &fileSecretStore{
+		path:       filepath.Join(dir, "secrets.enc"),
+		passphrase: passphrase,
+		secrets:    map[string]string{},
+	}
+
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *fileSecretStore) Get(key string) (string, error) {
+	value, ok := s.secrets[key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func (s *fileSecretStore) Set(key string, value string) error {
+	return s.update(func() bool {
+		if current, ok := s.secrets[key]; ok && current == value {
+			return false
+		}
+		s.secrets[key] = value
+		return true
+	})
+}
+
+func (s *fileSecretStore) Delete(key string) error {
+	return s.update(func() bool {
+		if _, ok := s.secrets[key]; !ok {
+			return false
+		}
+		delete(s.secrets, key)
+		return true
+	})
+}
+
+// update applies the change to the secrets as currently stored and
+// writes them back if the change reports that it modified them. The
+// file is locked throughout, so secrets stored by other processes in the
+// meantime are kept.
+func (s *fileSecretStore) update(change func() bool) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+
+	unlock, err := lockFile(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.read(); err != nil {
+		return err
+	}
+
+	if !change() {
+		return nil
+	}
+
+	return s.write()
+}
+
+// load reads and decrypts the secrets file, if it exists
+func (s *fileSecretStore) load() error {
+	unlock, err := lockFile(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return s.read()
+}
+
+// read reads and decrypts the secrets file without locking it
+func (s *fileSecretStore) read() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	var file encryptedSecretsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+
+	if file.Version != 1 {
+		return fmt.Errorf("unsupported secrets file version %d", file.Version)
+	}
+
+	// Deriving the key is slow, so it is only done again when the file
+	// was rewritten with a new salt
+	if s.key == nil || !bytes.Equal(s.salt, file.Salt) {
+		s.salt = file.Salt
+		if err := s.deriveKey(); err != nil {
+			return err
+		}
+	}
+
+	gcm, err := s.cipher()
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return errors.New("failed to decrypt secrets file: wrong passphrase or corrupted file")
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return err
+	}
+	s.secrets = secrets
+
+	return nil
+}
+
+// write encrypts all secrets and atomically replaces the secrets file.
+// The caller must hold the lock of the file.
+func (s *fileSecretStore) write() error {
+	if s.key == nil {
+		s.salt = make([]byte, saltSize)
+		if _, err := rand.Read(s.salt); err != nil {
+			return err
+		}
+		if err := s.deriveKey(); err != nil {
+			return err
+		}
+	}
+
+	gcm, err := s.cipher()
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(s.secrets)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(encryptedSecretsFile{
+		Version:    1,
+		Salt:       s.salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *fileSecretStore) deriveKey() error {
+	key, err := scrypt.Key([]byte(s.passphrase), s.salt, scryptN, scryptR, scryptP, scryptKeySize)
+	if err != nil {
+		return fmt.Errorf("failed to derive encryption key: %w", err)
+	}
+	s.key = key
+	return nil
+}
+
+func (s *fileSecretStore) cipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
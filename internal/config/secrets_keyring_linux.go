@@ -0,0 +1,278 @@
+//go:build linux
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	secretServiceName      = "org.freedesktop.secrets"
+	secretServicePath      = dbus.ObjectPath("/org/freedesktop/secrets")
+	secretServiceInterface = "org.freedesktop.Secret.Service"
+	secretItemInterface    = "org.freedesktop.Secret.Item"
+	secretPromptInterface  = "org.freedesktop.Secret.Prompt"
+	secretCollectionIface  = "org.freedesktop.Secret.Collection"
+	defaultCollectionPath  = dbus.ObjectPath("/org/freedesktop/secrets/aliases/default")
+
+	// keyringApplication identifies the items created by the CLI
+	keyringApplication = "pangolin-cli"
+
+	// keyringCallTimeout bounds calls that do not wait for the user
+	keyringCallTimeout = 5 * time.Second
+	// keyringPromptTimeout bounds how long to wait for the user to
+	// unlock the keyring
+	keyringPromptTimeout = 2 * time.Minute
+)
+
+// secretServiceSecret is the Secret struct of the Secret Service API
+type secretServiceSecret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// keyringSecretStore stores secrets in the system keyring through
+// the freedesktop.org Secret Service API over D-Bus
+type keyringSecretStore struct {
+	conn    *dbus.Conn
+	session dbus.ObjectPath
+}
+
+func openKeyringSecretStore() (*keyringSecretStore, error) {
+	// Running through sudo, the session bus belongs to the invoking
+	// user and cannot be used by root
+	if os.Geteuid() == 0 && os.Getenv("SUDO_USER") != "" {
+		return nil, errors.New("the keyring is not available when running with sudo")
+	}
+
+	address, err := sessionBusAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyringCallTimeout)
+	defer cancel()
+
+	conn, err := dbus.Connect(address, dbus.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the session bus: %w", err)
+	}
+
+	var output dbus.Variant
+	var session dbus.ObjectPath
+	err = conn.Object(secretServiceName, secretServicePath).
+		CallWithContext(ctx, secretServiceInterface+".OpenSession", 0, "plain", dbus.MakeVariant("")).
+		Store(&output, &session)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("secret service is not available: %w", err)
+	}
+
+	return &keyringSecretStore{
+		conn:    conn,
+		session: session,
+	}, nil
+}
+
+// sessionBusAddress returns the address of the session bus without
+// falling back to autolaunching a new bus
+func sessionBusAddress() (string, error) {
+	if address := os.Getenv("DBUS_SESSION_BUS_ADDRESS"); address != "" {
+		return address, nil
+	}
+
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		path := filepath.Join(runtimeDir, "bus")
+		if _, err := os.Stat(path); err == nil {
+			return "unix:path=" + path, nil
+		}
+	}
+
+	return "", errors.New("no session bus available")
+}
+
+func (s *keyringSecretStore) Get(key string) (string, error) {
+	item, err := s.findItem(key)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyringCallTimeout)
+	defer cancel()
+
+	var secret secretServiceSecret
+	err = s.conn.Object(secretServiceName, item).
+		CallWithContext(ctx, secretItemInterface+".GetSecret", 0, s.session).
+		Store(&secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret from keyring: %w", err)
+	}
+
+	return string(secret.Value), nil
+}
+
+func (s *keyringSecretStore) Set(key string, value string) error {
+	if err := s.unlock([]dbus.ObjectPath{defaultCollectionPath}); err != nil {
+		return err
+	}
+
+	properties := map[string]dbus.Variant{
+		"org.freedesktop.Secret.Item.Label":      dbus.MakeVariant("Pangolin CLI: " + key),
+		"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(keyringAttributes(key)),
+	}
+
+	secret := secretServiceSecret{
+		Session:     s.session,
+		Parameters:  []byte{},
+		Value:       []byte(value),
+		ContentType: "text/plain",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyringCallTimeout)
+	defer cancel()
+
+	var item, prompt dbus.ObjectPath
+	err := s.conn.Object(secretServiceName, defaultCollectionPath).
+		CallWithContext(ctx, secretCollectionIface+".CreateItem", 0, properties, secret, true).
+		Store(&item, &prompt)
+	if err != nil {
+		return fmt.Errorf("failed to store secret in keyring: %w", err)
+	}
+
+	return s.prompt(prompt)
+}
+
+func (s *keyringSecretStore) Delete(key string) error {
+	item, err := s.findItem(key)
+	if err != nil {
+		if errors.Is(err, ErrSecretNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyringCallTimeout)
+	defer cancel()
+
+	var prompt dbus.ObjectPath
+	err = s.conn.Object(secretServiceName, item).
+		CallWithContext(ctx, secretItemInterface+".Delete", 0).
+		Store(&prompt)
+	if err != nil {
+		return fmt.Errorf("failed to delete secret from keyring: %w", err)
+	}
+
+	return s.prompt(prompt)
+}
+
+// findItem returns the unlocked keyring item holding the secret for the key
+func (s *keyringSecretStore) findItem(key string) (dbus.ObjectPath, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), keyringCallTimeout)
+	defer cancel()
+
+	var unlocked, locked []dbus.ObjectPath
+	err := s.conn.Object(secretServiceName, secretServicePath).
+		CallWithContext(ctx, secretServiceInterface+".SearchItems", 0, keyringAttributes(key)).
+		Store(&unlocked, &locked)
+	if err != nil {
+		return "", fmt.Errorf("failed to search keyring: %w", err)
+	}
+
+	if len(unlocked) > 0 {
+		return unlocked[0], nil
+	}
+
+	if len(locked) > 0 {
+		if err := s.unlock(locked[:1]); err != nil {
+			return "", err
+		}
+		return locked[0], nil
+	}
+
+	return "", ErrSecretNotFound
+}
+
+// unlock unlocks the given objects, prompting the user if needed
+func (s *keyringSecretStore) unlock(objects []dbus.ObjectPath) error {
+	ctx, cancel := context.WithTimeout(context.Background(), keyringCallTimeout)
+	defer cancel()
+
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	err := s.conn.Object(secretServiceName, secretServicePath).
+		CallWithContext(ctx, secretServiceInterface+".Unlock", 0, objects).
+		Store(&unlocked, &prompt)
+	if err != nil {
+		return fmt.Errorf("failed to unlock keyring: %w", err)
+	}
+
+	return s.prompt(prompt)
+}
+
+// prompt shows a Secret Service prompt, if any, and waits for the
+// user to complete it
+func (s *keyringSecretStore) prompt(prompt dbus.ObjectPath) error {
+	if prompt == "" || prompt == "/" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyringPromptTimeout)
+	defer cancel()
+
+	matchOptions := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(prompt),
+		dbus.WithMatchInterface(secretPromptInterface),
+		dbus.WithMatchMember("Completed"),
+	}
+	if err := s.conn.AddMatchSignalContext(ctx, matchOptions...); err != nil {
+		return fmt.Errorf("failed to wait for keyring prompt: %w", err)
+	}
+	defer func() {
+		_ = s.conn.RemoveMatchSignal(matchOptions...)
+	}()
+
+	signals := make(chan *dbus.Signal, 1)
+	s.conn.Signal(signals)
+	defer s.conn.RemoveSignal(signals)
+
+	err := s.conn.Object(secretServiceName, prompt).
+		CallWithContext(ctx, secretPromptInterface+".Prompt", 0, "").
+		Store()
+	if err != nil {
+		return fmt.Errorf("failed to show keyring prompt: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.New("timed out waiting for the keyring to be unlocked")
+		case signal := <-signals:
+			if signal.Path != prompt || signal.Name != secretPromptInterface+".Completed" {
+				continue
+			}
+			if len(signal.Body) > 0 {
+				if dismissed, ok := signal.Body[0].(bool); ok && dismissed {
+					return errors.New("keyring prompt was dismissed")
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// keyringAttributes returns the lookup attributes of the item for the key
+func keyringAttributes(key string) map[string]string {
+	return map[string]string{
+		"application": keyringApplication,
+		"key":         key,
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !linux
+
+package config
+
+import "errors"
+
+// keyringSecretStore is only implemented on Linux, through the
+// Secret Service API
+type keyringSecretStore struct{}
+
+func openKeyringSecretStore() (*keyringSecretStore, error) {
+	return nil, errors.New("the keyring credential store is not supported on this platform")
+}
+
+func (s *keyringSecretStore) Get(key string) (string, error) {
+	return "", ErrSecretNotFound
+}
+
+func (s *keyringSecretStore) Set(key string, value string) error {
+	return errors.New("the keyring credential store is not supported on this platform")
+}
+
+func (s *keyringSecretStore) Delete(key string) error {
+	return nil
+}
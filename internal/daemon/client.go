@@ -0,0 +1,148 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrAlreadyRunning is returned by Start when a client is already running
+var ErrAlreadyRunning = errors.New("a client is already running")
+
+// StatusError is returned when the daemon rejects a request
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return e.Message
+}
+
+// Client handles communication with the daemon via Unix socket
+type Client struct {
+	socketPath string
+	httpClient *http.Client
+}
+
+// NewClient creates a new daemon socket client
+func NewClient(socketPath string) *Client {
+	if socketPath == "" {
+		socketPath = DefaultSocketPath
+	}
+
+	return &Client{
+		socketPath: socketPath,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+// doRequest performs an HTTP request and decodes the JSON response into
+// result, unless result is nil
+func (c *Client) doRequest(method, path string, payload any, result any) error {
+	var body io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequest(method, "http://localhost"+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if _, statErr := os.Stat(c.socketPath); os.IsNotExist(statErr) {
+			return fmt.Errorf("socket does not exist: %s (is the daemon running?)", c.socketPath)
+		}
+		return fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &StatusError{
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(message)),
+		}
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// IsRunning checks if the daemon is running and responding
+func (c *Client) IsRunning() bool {
+	if _, err := os.Stat(c.socketPath); err != nil {
+		return false
+	}
+
+	return c.doRequest("GET", "/health", nil, nil) == nil
+}
+
+// Status retrieves the state of the client of the named instance, or of
+// the default instance if the name is empty
+func (c *Client) Status(name string) (*StatusResponse, error) {
+	var status StatusResponse
+	if err := c.doRequest("GET", "/status?name="+url.QueryEscape(name), nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Start asks the daemon to start a client. Conflicts with other
+// instances are returned as a StatusError.
+func (c *Client) Start(req StartRequest) error {
+	err := c.doRequest("POST", "/start", req, nil)
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusConflict && statusErr.Message == ErrAlreadyRunning.Error() {
+		return ErrAlreadyRunning
+	}
+
+	return err
+}
+
+// Stop asks the daemon to stop the running client of the named
+// instance. It returns once the client has been signaled, before it has
+// exited.
+func (c *Client) Stop(name string) error {
+	return c.doRequest("POST", "/stop", StopRequest{Name: name}, nil)
+}
+
+// SwitchOrg asks the daemon to switch the organization of the running
+// client of the named instance
+func (c *Client) SwitchOrg(name string, orgID string) error {
+	return c.doRequest("POST", "/switch-org", SwitchOrgRequest{Name: name, OrgID: orgID}, nil)
+}
@@ -0,0 +1,89 @@
+// Package daemon implements the privileged daemon that runs the client
+// tunnel as root on behalf of unprivileged CLI invocations, and the client
+// used to talk to it.
+package daemon
+
+import "time"
+
+const (
+	// DefaultSocketPath is the Unix socket the daemon listens on
+	DefaultSocketPath = "/var/run/pangolin.sock"
+
+	// DefaultLogPath is the file the daemon logs to when it is started
+	// in the background
+	DefaultLogPath = "/var/log/pangolin-daemon.log"
+
+	// OlmIDEnvVar, OlmSecretEnvVar and UserTokenEnvVar hand the
+	// credentials to the client process started by the daemon. They are
+	// passed through the environment, which only root can read, rather
+	// than the command line, which is visible to every user.
+	OlmIDEnvVar     = "PANGOLIN_OLM_ID"
+	OlmSecretEnvVar = "PANGOLIN_OLM_SECRET"
+	UserTokenEnvVar = "PANGOLIN_USER_TOKEN"
+)
+
+// ServerConfig configures the daemon
+type ServerConfig struct {
+	// SocketPath is the Unix socket to listen on
+	SocketPath string
+	// AllowedUIDs are the users allowed to control the daemon in
+	// addition to root
+	AllowedUIDs []uint32
+	// ExitWhenIdle stops the daemon once the client it started exits
+	ExitWhenIdle bool
+}
+
+// StartRequest asks the daemon to start a client tunnel
+type StartRequest struct {
+	// Name is the name of the instance, empty for the default instance
+	Name      string `json:"name,omitempty"`
+	ID        string `json:"id"`
+	Secret    string `json:"secret"`
+	UserToken string `json:"userToken,omitempty"`
+	Endpoint  string `json:"endpoint"`
+	OrgID     string `json:"orgId,omitempty"`
+	// LogFile is the file the client logs to. It must be writable by
+	// the user making the request.
+	LogFile string `json:"logFile,omitempty"`
+	// SocketPath is the control socket of the client (default: the
+	// default client socket)
+	SocketPath string `json:"socketPath,omitempty"`
+	// Options are `up client` options keyed by profile key name, with
+	// values formatted as they would be given on the command line
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// SwitchOrgRequest asks the daemon to switch the organization of the
+// running client
+type SwitchOrgRequest struct {
+	Name  string `json:"name,omitempty"`
+	OrgID string `json:"orgId"`
+}
+
+// StopRequest asks the daemon to stop a running client
+type StopRequest struct {
+	Name string `json:"name,omitempty"`
+}
+
+// StatusResponse describes the client managed by the daemon
+type StatusResponse struct {
+	// Name is the name of the instance, empty for the default instance
+	Name string `json:"name,omitempty"`
+	// Running reports whether the daemon is running a client
+	Running  bool   `json:"running"`
+	PID      int    `json:"pid,omitempty"`
+	OrgID    string `json:"orgId,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+	// InterfaceName is the interface of the client, if it was not
+	// left to the default
+	InterfaceName string    `json:"interfaceName,omitempty"`
+	StartedAt     time.Time `json:"startedAt,omitzero"`
+	// LastExitCode is the exit code of the last client that exited,
+	// if any
+	LastExitCode *int `json:"lastExitCode,omitempty"`
+}
+
+// Response is the response to requests that change the client
+type Response struct {
+	Status string `json:"status"`
+}
@@ -0,0 +1,14 @@
+//go:build unix && !linux && !darwin
+
+package daemon
+
+import (
+	"errors"
+	"net"
+)
+
+// peerUID is not supported on this platform, so requests cannot be
+// authorized and are all refused
+func peerUID(conn *net.UnixConn) (uint32, error) {
+	return 0, errors.New("peer credentials are not supported on this platform")
+}
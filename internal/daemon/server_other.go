@@ -0,0 +1,18 @@
+//go:build !unix
+
+package daemon
+
+import (
+	"context"
+	"errors"
+)
+
+// Serve is not supported on this platform
+func Serve(ctx context.Context, cfg ServerConfig) error {
+	return errors.New("the daemon is not supported on this platform")
+}
+
+// StartDetached is not supported on this platform
+func StartDetached(executable string, args ...string) error {
+	return errors.New("the daemon is not supported on this platform")
+}
@@ -0,0 +1,597 @@
+//go:build unix
+
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/olm"
+)
+
+const (
+	// stopTimeout is how long the client is given to shut down cleanly
+	// before it is killed
+	stopTimeout = 10 * time.Second
+
+	// idleExitDelay is how long a daemon that exits when idle keeps
+	// running after the client exited, so that the exit code of the
+	// client can still be queried
+	idleExitDelay = 10 * time.Second
+)
+
+// server owns the client processes and serves requests to control them
+type server struct {
+	config     ServerConfig
+	executable string
+	idle       context.CancelFunc
+
+	mu sync.Mutex
+	// tunnels are the running clients keyed by instance name
+	tunnels map[string]*tunnel
+	// lastExitCodes are the exit codes of the last client of each
+	// instance that exited
+	lastExitCodes map[string]int
+}
+
+// tunnel is a client process started by the daemon
+type tunnel struct {
+	cmd           *exec.Cmd
+	orgID         string
+	endpoint      string
+	interfaceName string
+	socketPath    string
+	startedAt     time.Time
+	done          chan struct{}
+}
+
+type peerKey struct{}
+
+// peer is the user on the other end of a connection to the daemon
+type peer struct {
+	uid uint32
+	err error
+}
+
+// Serve runs the daemon until the context is canceled, or until the
+// client exits when the daemon was configured to exit when idle
+func Serve(ctx context.Context, cfg ServerConfig) error {
+	if os.Geteuid() != 0 {
+		return errors.New("the daemon must run as root")
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	if NewClient(cfg.SocketPath).IsRunning() {
+		return fmt.Errorf("a daemon is already listening on %s", cfg.SocketPath)
+	}
+
+	// Remove the socket left behind by a daemon that did not exit cleanly
+	if err := os.Remove(cfg.SocketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	listener, err := net.Listen("unix", cfg.SocketPath)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	// Every local user may connect, access is checked per request
+	// against the credentials of the connecting process
+	if err := os.Chmod(cfg.SocketPath, 0o666); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s := &server{
+		config:        cfg,
+		executable:    executable,
+		idle:          cancel,
+		tunnels:       map[string]*tunnel{},
+		lastExitCodes: map[string]int{},
+	}
+
+	httpServer := &http.Server{
+		Handler: s.routes(),
+		ConnContext: func(ctx context.Context, conn net.Conn) context.Context {
+			p := peer{err: errors.New("not a Unix socket connection")}
+			if unixConn, ok := conn.(*net.UnixConn); ok {
+				p.uid, p.err = peerUID(unixConn)
+			}
+			return context.WithValue(ctx, peerKey{}, p)
+		},
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("Daemon listening on %s", cfg.SocketPath)
+
+	err = httpServer.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+
+	s.shutdown()
+
+	return err
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("POST /start", s.handleStart)
+	mux.HandleFunc("POST /stop", s.handleStop)
+	mux.HandleFunc("POST /switch-org", s.handleSwitchOrg)
+
+	return s.authorize(mux)
+}
+
+// authorize only lets root and the allowed users through
+func (s *server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := r.Context().Value(peerKey{}).(peer)
+		if p.err != nil {
+			logger.Error("Failed to identify peer: %v", p.err)
+			http.Error(w, "unable to identify the connecting user", http.StatusForbidden)
+			return
+		}
+
+		if p.uid != 0 && !slices.Contains(s.config.AllowedUIDs, p.uid) {
+			logger.Warning("Rejected request from uid %d", p.uid)
+			http.Error(w, "permission denied: user is not allowed to control the daemon", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, Response{Status: "ok"})
+}
+
+func (s *server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := StatusResponse{Name: name}
+	if t := s.tunnels[name]; t != nil {
+		status.Running = true
+		status.PID = t.cmd.Process.Pid
+		status.OrgID = t.orgID
+		status.Endpoint = t.endpoint
+		status.InterfaceName = t.interfaceName
+		status.StartedAt = t.startedAt
+	}
+	if exitCode, ok := s.lastExitCodes[name]; ok {
+		status.LastExitCode = &exitCode
+	}
+
+	writeJSON(w, status)
+}
+
+// StartDetached starts the daemon in the background, in a session of
+// its own so that it outlives the caller and its terminal. The caller
+// must already run as root.
+func StartDetached(executable string, args ...string) error {
+	cmd := exec.Command(executable, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+func (s *server) handleStart(w http.ResponseWriter, r *http.Request) {
+	var req StartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, _ := r.Context().Value(peerKey{}).(peer)
+
+	args, err := clientArgs(req, p.uid)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tunnels[req.Name] != nil || olm.NewClient(req.SocketPath).IsRunning() {
+		http.Error(w, ErrAlreadyRunning.Error(), http.StatusConflict)
+		return
+	}
+
+	// Instances must not share the socket or interface of another
+	interfaceName := req.Options["interface_name"]
+	for name, t := range s.tunnels {
+		if t.socketPath == req.SocketPath || (interfaceName != "" && t.interfaceName == interfaceName) {
+			http.Error(w, fmt.Sprintf("instance %q uses the same socket or interface", name), http.StatusConflict)
+			return
+		}
+	}
+
+	cmd := exec.Command(s.executable, args...)
+	cmd.Env = append(os.Environ(),
+		OlmIDEnvVar+"="+req.ID,
+		OlmSecretEnvVar+"="+req.Secret,
+		UserTokenEnvVar+"="+req.UserToken,
+	)
+	if req.LogFile != "" {
+		logFileKey, _ := config.LookupKey("log_file")
+		cmd.Env = append(cmd.Env, logFileKey.EnvVar()+"="+req.LogFile)
+	}
+
+	if err := cmd.Start(); err != nil {
+		logger.Error("Failed to start client: %v", err)
+		http.Error(w, "failed to start client: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	delete(s.lastExitCodes, req.Name)
+
+	t := &tunnel{
+		cmd:           cmd,
+		orgID:         req.OrgID,
+		endpoint:      req.Endpoint,
+		interfaceName: interfaceName,
+		socketPath:    req.SocketPath,
+		startedAt:     time.Now(),
+		done:          make(chan struct{}),
+	}
+	s.tunnels[req.Name] = t
+
+	logger.Info("Started client %s for uid %d (pid %d)", instanceName(req.Name), p.uid, cmd.Process.Pid)
+
+	go s.wait(req.Name, t)
+
+	writeJSON(w, Response{Status: "started"})
+}
+
+func (s *server) handleStop(w http.ResponseWriter, r *http.Request) {
+	var req StopRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	t := s.tunnels[req.Name]
+	s.mu.Unlock()
+
+	if t == nil {
+		http.Error(w, "no client is currently running", http.StatusConflict)
+		return
+	}
+
+	t.stop()
+
+	writeJSON(w, Response{Status: "stopping"})
+}
+
+func (s *server) handleSwitchOrg(w http.ResponseWriter, r *http.Request) {
+	var req SwitchOrgRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.OrgID == "" {
+		http.Error(w, "invalid request: organization ID is required", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.tunnels[req.Name]
+	if t == nil {
+		http.Error(w, "no client is currently running", http.StatusConflict)
+		return
+	}
+
+	if _, err := olm.NewClient(t.socketPath).SwitchOrg(req.OrgID); err != nil {
+		http.Error(w, "failed to switch organization: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	t.orgID = req.OrgID
+
+	writeJSON(w, Response{Status: "switching"})
+}
+
+// wait waits for the client process of the instance to exit
+func (s *server) wait(name string, t *tunnel) {
+	err := t.cmd.Wait()
+	close(t.done)
+
+	if err != nil {
+		logger.Warning("Client %s exited: %v", instanceName(name), err)
+	} else {
+		logger.Info("Client %s exited", instanceName(name))
+	}
+
+	s.mu.Lock()
+	if s.tunnels[name] == t {
+		delete(s.tunnels, name)
+		s.lastExitCodes[name] = t.cmd.ProcessState.ExitCode()
+	}
+	s.mu.Unlock()
+
+	if s.config.ExitWhenIdle {
+		time.AfterFunc(idleExitDelay, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			// Keep running if another client was started meanwhile
+			if len(s.tunnels) == 0 {
+				s.idle()
+			}
+		})
+	}
+}
+
+// shutdown stops the running clients and waits for them to exit
+func (s *server) shutdown() {
+	s.mu.Lock()
+	tunnels := slices.Collect(maps.Values(s.tunnels))
+	s.mu.Unlock()
+
+	for _, t := range tunnels {
+		t.stop()
+	}
+	for _, t := range tunnels {
+		<-t.done
+	}
+}
+
+// stop asks the client process to shut down, and kills it if it does
+// not exit in time
+func (t *tunnel) stop() {
+	_ = t.cmd.Process.Signal(syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-t.done:
+		case <-time.After(stopTimeout):
+			_ = t.cmd.Process.Kill()
+		}
+	}()
+}
+
+// instanceName returns the name of the instance for log messages
+func instanceName(name string) string {
+	if name == "" {
+		return "default"
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+// clientArgs validates the start request of the given user and returns
+// the arguments to start the client process with. Credentials are not
+// part of the arguments.
+func clientArgs(req StartRequest, uid uint32) ([]string, error) {
+	if req.ID == "" || req.Secret == "" {
+		return nil, errors.New("client ID and secret are required")
+	}
+	if req.Endpoint == "" {
+		return nil, errors.New("endpoint is required")
+	}
+	if req.Name != "" {
+		if err := config.ValidateInstanceName(req.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	// The project file was already applied and hooks are run by the
+	// requesting user, not by the client running as root
+	args := []string{"up", "client", "--no-project", "--no-hooks", "--endpoint", req.Endpoint}
+	if req.OrgID != "" {
+		args = append(args, "--org", req.OrgID)
+	}
+
+	for name, value := range req.Options {
+		key, err := config.LookupProfileKey(name)
+		if err != nil {
+			return nil, err
+		}
+		if key.Name == "org" || key.Name == "endpoint" {
+			return nil, fmt.Errorf("%s cannot be given as an option", key.Name)
+		}
+		if _, err := key.Parse(value); err != nil {
+			return nil, err
+		}
+
+		// The client runs as root, so it must only read files the
+		// requesting user can read
+		if key.Name == "tls_client_cert" && value != "" {
+			if err := checkReadable(value, uid); err != nil {
+				return nil, err
+			}
+		}
+
+		args = append(args, fmt.Sprintf("--%s=%s", key.FlagName(), value))
+	}
+
+	if req.LogFile != "" {
+		if err := checkLogFile(req.LogFile, uid); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.SocketPath != "" {
+		if err := checkSocketPath(req.SocketPath, uid); err != nil {
+			return nil, err
+		}
+		args = append(args, "--socket", req.SocketPath)
+	}
+
+	return args, nil
+}
+
+// checkReadable verifies that the file is readable by the given user.
+// Group permissions are not considered.
+func checkReadable(path string, uid uint32) error {
+	if uid == 0 {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fmt.Errorf("unable to check permissions of %s", path)
+	}
+
+	mode := info.Mode().Perm()
+	if (stat.Uid == uid && mode&0o400 != 0) || mode&0o004 != 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%s is not readable by the requesting user", path)
+}
+
+// checkLogFile verifies that the client, which runs as root, can write
+// the log file on behalf of the given user without being tricked into
+// overwriting a file the user has no access to
+func checkLogFile(path string, uid uint32) error {
+	if uid == 0 {
+		return nil
+	}
+
+	if !filepath.IsAbs(path) {
+		return errors.New("log file must be an absolute path")
+	}
+
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("invalid log file directory: %w", err)
+	}
+
+	dirInfo, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if stat, ok := dirInfo.Sys().(*syscall.Stat_t); !ok || stat.Uid != uid {
+		return fmt.Errorf("log file directory %s must be owned by the requesting user", dir)
+	}
+
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("log file %s must be a regular file", path)
+	}
+
+	// Log files created by earlier clients are owned by root. Hard links
+	// are refused since they can point to files outside the directory.
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || (stat.Uid != uid && stat.Uid != 0) || stat.Nlink != 1 {
+		return fmt.Errorf("log file %s must be owned by the requesting user", path)
+	}
+
+	return nil
+}
+
+// checkSocketPath verifies that the client, which runs as root, can
+// create its control socket at the path on behalf of the given user. An
+// existing file at the path is replaced by the client, so the path must
+// either be a client socket in the default socket directory, or be in a
+// directory owned by the user, where the user could replace it as well.
+func checkSocketPath(path string, uid uint32) error {
+	if !filepath.IsAbs(path) {
+		return errors.New("socket path must be absolute")
+	}
+
+	if uid == 0 {
+		return nil
+	}
+
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("invalid socket directory: %w", err)
+	}
+
+	if defaultDir, err := filepath.EvalSymlinks(filepath.Dir(olm.GetDefaultSocketPath())); err == nil && dir == defaultDir {
+		if !isClientSocketName(filepath.Base(path)) {
+			return fmt.Errorf("socket %s is not a client socket", path)
+		}
+	} else {
+		dirInfo, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+		if stat, ok := dirInfo.Sys().(*syscall.Stat_t); !ok || stat.Uid != uid {
+			return fmt.Errorf("socket directory %s must be owned by the requesting user", dir)
+		}
+	}
+
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	return nil
+}
+
+// isClientSocketName reports whether the file name is that of the
+// default client socket or of the socket of a named instance
+func isClientSocketName(name string) bool {
+	defaultName := filepath.Base(olm.GetDefaultSocketPath())
+	if name == defaultName {
+		return true
+	}
+
+	ext := filepath.Ext(defaultName)
+	instanceName, ok := strings.CutPrefix(name, strings.TrimSuffix(defaultName, ext)+"-")
+	if !ok {
+		return false
+	}
+	instanceName, ok = strings.CutSuffix(instanceName, ext)
+	return ok && config.ValidateInstanceName(instanceName) == nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
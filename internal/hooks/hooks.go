@@ -0,0 +1,194 @@
+// Package hooks runs the commands configured for connection events, such
+// as mounting shares once the tunnel is up.
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/olm"
+)
+
+// Event is a connection event that hooks run on
+type Event string
+
+const (
+	PreUp         Event = "pre_up"
+	PostUp        Event = "post_up"
+	PreDown       Event = "pre_down"
+	PostDown      Event = "post_down"
+	PostOrgSwitch Event = "post_org_switch"
+)
+
+// Environment variables describing the connection to hooks
+const (
+	EventEnvVar         = "PANGOLIN_HOOK"
+	InstanceEnvVar      = "PANGOLIN_INSTANCE"
+	OrgIDEnvVar         = "PANGOLIN_ORG_ID"
+	PreviousOrgIDEnvVar = "PANGOLIN_PREVIOUS_ORG_ID"
+	InterfaceEnvVar     = "PANGOLIN_INTERFACE"
+	EndpointEnvVar      = "PANGOLIN_ENDPOINT"
+	PeersEnvVar         = "PANGOLIN_PEERS"
+)
+
+// waitDelay is how long to wait for the output of a hook to be closed
+// after it was killed, since commands it started may still hold it open
+const waitDelay = 5 * time.Second
+
+// Connection describes the connection an event happened on. Fields that
+// are not known are left empty and their environment variables unset.
+type Connection struct {
+	// Instance is the name of the client instance, empty for the
+	// default instance
+	Instance string
+	OrgID    string
+	// PreviousOrgID is the organization before an organization switch
+	PreviousOrgID string
+	InterfaceName string
+	Endpoint      string
+	// Peers are the peers of the connection, if it is registered
+	Peers map[int]*olm.OLMPeerStatus
+}
+
+// peer is a peer as described to hooks in PeersEnvVar
+type peer struct {
+	SiteID    int    `json:"siteId"`
+	Name      string `json:"name"`
+	Address   string `json:"address,omitempty"`
+	Endpoint  string `json:"endpoint,omitempty"`
+	Connected bool   `json:"connected"`
+}
+
+// Commands returns the commands configured for the event, the global
+// ones first, followed by those of the organization. Organization IDs
+// are matched case-insensitively, since the keys of the configuration
+// file are lowercased when it is read.
+func Commands(hooks config.Hooks, event Event, orgID string) []string {
+	commands := slices.Clone(eventCommands(hooks.HookCommands, event))
+	if orgID == "" {
+		return commands
+	}
+
+	for id, orgHooks := range hooks.Orgs {
+		if strings.EqualFold(id, orgID) {
+			commands = append(commands, eventCommands(orgHooks, event)...)
+		}
+	}
+	return commands
+}
+
+// eventCommands returns the commands of the event
+func eventCommands(commands config.HookCommands, event Event) []string {
+	switch event {
+	case PreUp:
+		return commands.PreUp
+	case PostUp:
+		return commands.PostUp
+	case PreDown:
+		return commands.PreDown
+	case PostDown:
+		return commands.PostDown
+	case PostOrgSwitch:
+		return commands.PostOrgSwitch
+	default:
+		return nil
+	}
+}
+
+// Run runs the commands configured for the event one after another.
+// Failures are logged and do not stop the remaining commands, so that a
+// broken hook cannot keep the tunnel from coming up or going down.
+func Run(hooks config.Hooks, event Event, conn Connection) {
+	commands := Commands(hooks, event, conn.OrgID)
+	if len(commands) == 0 {
+		return
+	}
+
+	env := append(os.Environ(), conn.environment(event)...)
+	timeout := hooks.TimeoutDuration()
+
+	for _, command := range commands {
+		logger.Debug("Running %s hook: %s", event, command)
+
+		if err := runCommand(command, env, timeout); err != nil {
+			logger.Warning("Hook %s failed: %s: %v", event, command, err)
+		}
+	}
+}
+
+// runCommand runs the command through the shell, killing it once the
+// timeout expires
+func runCommand(command string, env []string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	}
+	cmd.Env = env
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.WaitDelay = waitDelay
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errors.New("timed out after " + timeout.String())
+	}
+	return err
+}
+
+// environment returns the environment variables describing the
+// connection
+func (c Connection) environment(event Event) []string {
+	env := []string{EventEnvVar + "=" + string(event)}
+
+	vars := []struct {
+		name  string
+		value string
+	}{
+		{InstanceEnvVar, c.Instance},
+		{OrgIDEnvVar, c.OrgID},
+		{PreviousOrgIDEnvVar, c.PreviousOrgID},
+		{InterfaceEnvVar, c.InterfaceName},
+		{EndpointEnvVar, c.Endpoint},
+	}
+	for _, v := range vars {
+		if v.value != "" {
+			env = append(env, v.name+"="+v.value)
+		}
+	}
+
+	if c.Peers != nil {
+		peers := make([]peer, 0, len(c.Peers))
+		for _, status := range c.Peers {
+			peers = append(peers, peer{
+				SiteID:    status.SiteID,
+				Name:      status.SiteName,
+				Address:   status.PeerIP,
+				Endpoint:  status.Endpoint,
+				Connected: status.Connected,
+			})
+		}
+		slices.SortFunc(peers, func(a, b peer) int {
+			return a.SiteID - b.SiteID
+		})
+
+		if data, err := json.Marshal(peers); err == nil {
+			env = append(env, PeersEnvVar+"="+string(data))
+		}
+	}
+
+	return env
+}
@@ -75,6 +75,11 @@ func GetLogger() *Logger {
 	return globalLogger
 }
 
+// IsDebug reports whether debug messages are logged
+func (l *Logger) IsDebug() bool {
+	return l.level == LogLevelDebug
+}
+
 // Info logs an info message
 func (l *Logger) Info(format string, args ...any) {
 	message := fmt.Sprintf(format, args...)
@@ -153,3 +158,8 @@ func Error(format string, args ...any) {
 func Warning(format string, args ...any) {
 	GetLogger().Warning(format, args...)
 }
+
+// IsDebug reports whether the global logger logs debug messages
+func IsDebug() bool {
+	return GetLogger().IsDebug()
+}
@@ -0,0 +1,214 @@
+// Package metrics exports the status of a client in the Prometheus text
+// exposition format.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/olm"
+	"github.com/fosrl/cli/internal/version"
+)
+
+// contentType is the content type of the Prometheus text format
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metric describes a metric family
+type metric struct {
+	name string
+	help string
+}
+
+var (
+	buildInfo        = metric{"pangolin_build_info", "Build information of the CLI, always 1."}
+	clientUp         = metric{"pangolin_client_up", "Whether the control API of the client could be reached."}
+	clientInfo       = metric{"pangolin_client_info", "Information about the running client, always 1."}
+	clientConnected  = metric{"pangolin_client_connected", "Whether the client is connected."}
+	clientRegistered = metric{"pangolin_client_registered", "Whether the client is registered with the server."}
+	siteConnected    = metric{"pangolin_site_connected", "Whether the site is connected."}
+	siteRTT          = metric{"pangolin_site_rtt_seconds", "Round trip time to the site in seconds."}
+	siteLastSeenAge  = metric{"pangolin_site_last_seen_age_seconds", "Seconds since the site was last seen."}
+	siteRelay        = metric{"pangolin_site_relay", "Whether the site is reached through the relay."}
+)
+
+// Serve serves the metrics of the client on /metrics at the listen
+// address until the context is canceled
+func Serve(ctx context.Context, listenAddr string, client *olm.Client) error {
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", listenAddr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", Handler(client))
+
+	httpServer := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("Serving metrics on http://%s/metrics", listener.Addr())
+
+	err = httpServer.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Handler returns an HTTP handler serving the metrics of the client on
+// every request. The status is fetched when scraped, so the handler keeps
+// serving while the client is down and reports it as not up.
+func Handler(client *olm.Client) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, err := client.GetStatus()
+		if err != nil {
+			logger.Debug("Failed to get client status: %v", err)
+			status = nil
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		Write(w, status, time.Now())
+	})
+}
+
+// Write writes the metrics of the client status in the Prometheus text
+// format. A nil status reports a client that is not up.
+func Write(w io.Writer, status *olm.StatusResponse, now time.Time) {
+	m := &writer{w: w}
+
+	m.gauge(buildInfo, []label{{"version", version.Version}}, 1)
+
+	if status == nil {
+		m.gauge(clientUp, nil, 0)
+		return
+	}
+	m.gauge(clientUp, nil, 1)
+
+	m.gauge(clientInfo, []label{
+		{"org_id", status.OrgID},
+		{"agent", status.Agent},
+		{"version", status.Version},
+	}, 1)
+	m.gauge(clientConnected, nil, boolValue(status.Connected))
+	m.gauge(clientRegistered, nil, boolValue(status.Registered))
+
+	peers := slices.Sorted(maps.Keys(status.PeerStatuses))
+
+	m.family(siteConnected, func() {
+		for _, id := range peers {
+			peer := status.PeerStatuses[id]
+			m.sample(siteConnected, siteLabels(peer), boolValue(peer.Connected))
+		}
+	})
+	m.family(siteRTT, func() {
+		for _, id := range peers {
+			peer := status.PeerStatuses[id]
+			if peer.RTT > 0 {
+				m.sample(siteRTT, siteLabels(peer), peer.RTT.Seconds())
+			}
+		}
+	})
+	m.family(siteLastSeenAge, func() {
+		for _, id := range peers {
+			peer := status.PeerStatuses[id]
+			if !peer.LastSeen.IsZero() {
+				m.sample(siteLastSeenAge, siteLabels(peer), max(0, now.Sub(peer.LastSeen).Seconds()))
+			}
+		}
+	})
+	m.family(siteRelay, func() {
+		for _, id := range peers {
+			peer := status.PeerStatuses[id]
+			m.sample(siteRelay, siteLabels(peer), boolValue(peer.IsRelay))
+		}
+	})
+}
+
+// label is a label of a sample
+type label struct {
+	name  string
+	value string
+}
+
+// siteLabels returns the labels identifying the site of the peer
+func siteLabels(peer *olm.OLMPeerStatus) []label {
+	return []label{
+		{"site_id", strconv.Itoa(peer.SiteID)},
+		{"site", peer.SiteName},
+	}
+}
+
+// writer writes metric families and their samples
+type writer struct {
+	w io.Writer
+}
+
+// gauge writes a metric family with a single sample
+func (m *writer) gauge(metric metric, labels []label, value float64) {
+	m.family(metric, func() {
+		m.sample(metric, labels, value)
+	})
+}
+
+// family writes the help and type of the metric, followed by the samples
+// written by the function
+func (m *writer) family(metric metric, samples func()) {
+	fmt.Fprintf(m.w, "# HELP %s %s\n", metric.name, metric.help)
+	fmt.Fprintf(m.w, "# TYPE %s gauge\n", metric.name)
+	samples()
+}
+
+// sample writes a sample of the metric
+func (m *writer) sample(metric metric, labels []label, value float64) {
+	var sb strings.Builder
+	sb.WriteString(metric.name)
+
+	if len(labels) > 0 {
+		sb.WriteString("{")
+		for i, l := range labels {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			sb.WriteString(l.name)
+			sb.WriteString(`="`)
+			sb.WriteString(labelValueEscaper.Replace(l.value))
+			sb.WriteString(`"`)
+		}
+		sb.WriteString("}")
+	}
+
+	sb.WriteString(" ")
+	sb.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	sb.WriteString("\n")
+
+	io.WriteString(m.w, sb.String())
+}
+
+// labelValueEscaper escapes label values as required by the text format
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// boolValue returns 1 for true and 0 for false
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
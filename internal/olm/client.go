@@ -15,6 +15,10 @@ import (
 const (
 	defaultSocketPath = "/var/run/olm.sock"
 	AgentName         = "Pangolin CLI"
+
+	// DefaultInterfaceName is the interface created by a client that was
+	// not given another name
+	DefaultInterfaceName = "pangolin"
 )
 
 // Client handles communication with the OLM process via Unix socket
@@ -91,8 +95,48 @@ func GetDefaultSocketPath() string {
 	return getDefaultSocketPath()
 }
 
+// SocketPath returns the path of the socket the client connects to
+func (c *Client) SocketPath() string {
+	return c.socketPath
+}
+
+// CheckSocket verifies that the socket, if it exists, is owned by root
+// or the current user. A socket owned by anyone else may belong to
+// another user's client, or to a process impersonating one.
+func (c *Client) CheckSocket() error {
+	return checkSocket(c.socketPath)
+}
+
+// checkSocket verifies that the socket at the path, if it exists, is
+// owned by root or the current user
+func checkSocket(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+
+	uid, ok := fileOwner(info)
+	if !ok {
+		return nil
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s is not a socket", path)
+	}
+
+	if uid != 0 && int(uid) != os.Geteuid() {
+		return fmt.Errorf("socket %s is owned by uid %d, expected root or uid %d; it may belong to a client of another user", path, uid, os.Geteuid())
+	}
+
+	return nil
+}
+
 // doRequest performs an HTTP request and handles common error cases
 func (c *Client) doRequest(method, path string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	if err := c.CheckSocket(); err != nil {
+		return nil, err
+	}
+
 	req, err := http.NewRequest(method, "http://localhost"+path, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -0,0 +1,237 @@
+package olm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// EventType is the kind of change an event reports
+type EventType string
+
+const (
+	EventRegistered       EventType = "registered"
+	EventPeerConnected    EventType = "peer_connected"
+	EventPeerDisconnected EventType = "peer_disconnected"
+	EventRelayFallback    EventType = "relay_fallback"
+	EventOrgSwitched      EventType = "org_switched"
+	EventAuthError        EventType = "auth_error"
+	EventTerminating      EventType = "terminating"
+)
+
+// eventPollInterval is how often the status is polled to derive events
+const eventPollInterval = 500 * time.Millisecond
+
+// Event is a change in the state of the client
+type Event struct {
+	Type  EventType `json:"type"`
+	Time  time.Time `json:"time"`
+	OrgID string    `json:"orgId,omitempty"`
+	// Peer is the peer the event is about, for peer events
+	Peer *OLMPeerStatus `json:"peer,omitempty"`
+	// Message describes the event, such as the reason of an
+	// authentication error
+	Message string `json:"message,omitempty"`
+}
+
+// Subscribe streams the events of the client until the context is
+// canceled or the client goes away, then closes the channel. Clients
+// started by the CLI publish their events on their events socket,
+// including authentication errors. Other clients are asked for the
+// /events endpoint of their control API, which the control API does not
+// serve yet, so they are polled instead: events are derived from the
+// changes in their status, starting with their current state as if they
+// had just come up, and authentication errors are never reported.
+func (c *Client) Subscribe(ctx context.Context) (<-chan Event, error) {
+	if err := c.CheckSocket(); err != nil {
+		return nil, err
+	}
+
+	eventsPath := EventsSocketPath(c.socketPath)
+	if err := checkSocket(eventsPath); err != nil {
+		return nil, err
+	}
+
+	// An events socket left behind by a client that crashed refuses
+	// connections, so the control API is tried next
+	if resp, err := openEventStream(ctx, eventsPath); err == nil {
+		if resp.StatusCode == http.StatusOK {
+			events := make(chan Event)
+			go streamEvents(ctx, resp.Body, events)
+			return events, nil
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := openEventStream(ctx, c.socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to socket: %w", err)
+	}
+
+	events := make(chan Event)
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		go c.pollEvents(ctx, events)
+		return events, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	go streamEvents(ctx, resp.Body, events)
+
+	return events, nil
+}
+
+// openEventStream requests the event stream served on the socket. The
+// stream stays open, so no request timeout applies to it.
+func openEventStream(ctx context.Context, socketPath string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://localhost/events", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/x-ndjson, text/event-stream")
+
+	streamClient := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+
+	return streamClient.Do(req)
+}
+
+// streamEvents decodes events from an NDJSON or server-sent events
+// stream. Lines that are not events, such as SSE comments, are skipped.
+func streamEvents(ctx context.Context, body io.ReadCloser, events chan<- Event) {
+	defer close(events)
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if data, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			line = bytes.TrimSpace(data)
+		}
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+
+		var event Event
+		if err := json.Unmarshal(line, &event); err != nil || event.Type == "" {
+			continue
+		}
+		if event.Time.IsZero() {
+			event.Time = time.Now()
+		}
+
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pollEvents polls the status of the client and sends the events
+// derived from its changes. Once the client can no longer be reached, a
+// terminating event is sent unless one was already derived.
+func (c *Client) pollEvents(ctx context.Context, events chan<- Event) {
+	defer close(events)
+
+	ticker := time.NewTicker(eventPollInterval)
+	defer ticker.Stop()
+
+	previous := &StatusResponse{}
+	for {
+		status, err := c.GetStatus()
+		if err != nil {
+			if !previous.Terminated {
+				select {
+				case events <- Event{Type: EventTerminating, Time: time.Now(), OrgID: previous.OrgID}:
+				case <-ctx.Done():
+				}
+			}
+			return
+		}
+
+		for _, event := range diffStatus(previous, status) {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		previous = status
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// diffStatus returns the events that lead from the previous status of
+// the client to the current one. Peer events are ordered by site ID.
+func diffStatus(previous, current *StatusResponse) []Event {
+	now := time.Now()
+	var events []Event
+
+	if previous.OrgID != "" && current.OrgID != previous.OrgID {
+		events = append(events, Event{Type: EventOrgSwitched, Time: now, OrgID: current.OrgID, Message: "switched from " + previous.OrgID})
+	}
+
+	if current.Registered && (!previous.Registered || current.OrgID != previous.OrgID) {
+		events = append(events, Event{Type: EventRegistered, Time: now, OrgID: current.OrgID})
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(current.PeerStatuses)) {
+		peer := current.PeerStatuses[id]
+		before := previous.PeerStatuses[id]
+		wasConnected := before != nil && before.Connected
+		wasRelayed := before != nil && before.IsRelay
+
+		switch {
+		case peer.Connected && !wasConnected:
+			events = append(events, Event{Type: EventPeerConnected, Time: now, OrgID: current.OrgID, Peer: peer})
+		case !peer.Connected && wasConnected:
+			events = append(events, Event{Type: EventPeerDisconnected, Time: now, OrgID: current.OrgID, Peer: peer})
+		}
+
+		if peer.IsRelay && !wasRelayed {
+			events = append(events, Event{Type: EventRelayFallback, Time: now, OrgID: current.OrgID, Peer: peer})
+		}
+	}
+
+	// Peers that went away while connected
+	for _, id := range slices.Sorted(maps.Keys(previous.PeerStatuses)) {
+		peer := previous.PeerStatuses[id]
+		if _, ok := current.PeerStatuses[id]; !ok && peer.Connected {
+			gone := *peer
+			gone.Connected = false
+			events = append(events, Event{Type: EventPeerDisconnected, Time: now, OrgID: previous.OrgID, Peer: &gone})
+		}
+	}
+
+	if current.Terminated && !previous.Terminated {
+		events = append(events, Event{Type: EventTerminating, Time: now, OrgID: current.OrgID})
+	}
+
+	return events
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package olm
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileOwner returns the uid of the owner of the file
+func fileOwner(info os.FileInfo) (uint32, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return stat.Uid, true
+}
@@ -0,0 +1,222 @@
+package olm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// eventBufferSize is how many events are queued for a subscriber
+	// before it is considered too slow and disconnected
+	eventBufferSize = 64
+	// publisherCloseTimeout bounds how long Close waits for the queued
+	// events to be written to the subscribers
+	publisherCloseTimeout = time.Second
+)
+
+// EventsSocketPath returns the socket on which the client with the given
+// control socket publishes its events
+func EventsSocketPath(socketPath string) string {
+	return socketPath + ".events"
+}
+
+// Publisher serves the events of the process running the tunnel on the
+// events socket of the client. Events reported by the tunnel callbacks,
+// such as authentication errors, are published as they happen. Changes
+// in registration, peers and organization are only exposed through the
+// status of the client, so they are derived from it by Watch, once for
+// all subscribers.
+type Publisher struct {
+	socketPath string
+	server     *http.Server
+
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+	// status is the last status seen by Watch, from which new
+	// subscribers receive the current state
+	status     *StatusResponse
+	terminated bool
+	closed     bool
+}
+
+// NewPublisher listens on the events socket of the client with the given
+// control socket. A socket left behind by an earlier client is replaced.
+func NewPublisher(socketPath string) (*Publisher, error) {
+	path := EventsSocketPath(socketPath)
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale events socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+
+	// Anyone who can query the status of the client may follow its
+	// events, which carry no credentials
+	if err := os.Chmod(path, 0o666); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set events socket permissions: %w", err)
+	}
+
+	p := &Publisher{
+		socketPath:  path,
+		subscribers: map[chan Event]struct{}{},
+		status:      &StatusResponse{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /events", p.handleEvents)
+	p.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		_ = p.server.Serve(listener)
+	}()
+
+	return p, nil
+}
+
+// Publish sends the event to all subscribers. Subscribers that fall
+// behind are disconnected.
+func (p *Publisher) Publish(event Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.publish(event)
+}
+
+// publish sends the event with the lock held. A client terminates only
+// once, so later terminating events are dropped.
+func (p *Publisher) publish(event Event) {
+	if event.Type == EventTerminating {
+		if p.terminated {
+			return
+		}
+		p.terminated = true
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	for events := range p.subscribers {
+		select {
+		case events <- event:
+		default:
+			delete(p.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+// Watch publishes the events derived from the changes in the status of
+// the client until the context is canceled
+func (p *Publisher) Watch(ctx context.Context, client *Client) {
+	ticker := time.NewTicker(eventPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// The control API is not available until the tunnel has
+		// started, so errors are expected early on
+		if status, err := client.GetStatus(); err == nil {
+			p.mu.Lock()
+			for _, event := range diffStatus(p.status, status) {
+				p.publish(event)
+			}
+			p.status = status
+			p.mu.Unlock()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close disconnects the subscribers once the events published so far
+// have been written to them, then removes the events socket
+func (p *Publisher) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	for events := range p.subscribers {
+		close(events)
+	}
+	clear(p.subscribers)
+	p.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), publisherCloseTimeout)
+	defer cancel()
+	_ = p.server.Shutdown(ctx)
+	_ = os.Remove(p.socketPath)
+}
+
+// handleEvents streams the events to the subscriber as NDJSON, starting
+// with the current state of the client as if it had just come up
+func (p *Publisher) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		http.Error(w, "client is exiting", http.StatusServiceUnavailable)
+		return
+	}
+
+	initial := diffStatus(&StatusResponse{}, p.status)
+	events := make(chan Event, len(initial)+eventBufferSize)
+	for _, event := range initial {
+		events <- event
+	}
+	p.subscribers[events] = struct{}{}
+	p.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	encoder := json.NewEncoder(w)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := encoder.Encode(event); err != nil {
+				p.unsubscribe(events)
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			p.unsubscribe(events)
+			return
+		}
+	}
+}
+
+// unsubscribe removes the subscriber, unless it was already removed
+func (p *Publisher) unsubscribe(events chan Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.subscribers, events)
+}
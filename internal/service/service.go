@@ -0,0 +1,113 @@
+// Package service installs the client as a systemd service, so that the
+// tunnel is brought up on boot and restarted by systemd.
+package service
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+const (
+	// UnitName is the name of the systemd unit running the client
+	UnitName = "pangolin-client.service"
+	// UnitPath is where the unit file is installed
+	UnitPath = "/etc/systemd/system/" + UnitName
+	// EnvironmentFilePath holds the credentials of the client. It is
+	// only readable by root.
+	EnvironmentFilePath = "/etc/pangolin/client.env"
+
+	// ServiceEnvVar is set in the environment of the client started by
+	// systemd, so that it knows it is the client managed by the unit
+	ServiceEnvVar = "PANGOLIN_SERVICE"
+)
+
+// UnitConfig describes the client run by the systemd unit
+type UnitConfig struct {
+	// Description is the human-readable description of the unit
+	Description string
+	// Executable is the absolute path of the CLI binary
+	Executable string
+	// Args are the arguments the CLI is run with
+	Args []string
+	// EnvironmentFile is the file holding the client credentials
+	EnvironmentFile string
+}
+
+// ClientArgs returns the arguments that run the client in the foreground
+// with the given organization, endpoint and `up client` options keyed by
+// profile key name. Options are sorted so that the arguments are stable.
+func ClientArgs(orgID string, endpoint string, options map[string]string) []string {
+	args := []string{"up", "client", "--attach", "--no-project", "--endpoint", endpoint}
+	if orgID != "" {
+		args = append(args, "--org", orgID)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(options)) {
+		flag := strings.ReplaceAll(name, "_", "-")
+		args = append(args, fmt.Sprintf("--%s=%s", flag, options[name]))
+	}
+
+	return args
+}
+
+// UnitFile returns the contents of the systemd unit file for the
+// given configuration
+func UnitFile(cfg UnitConfig) string {
+	execStart := make([]string, 0, len(cfg.Args)+1)
+	execStart = append(execStart, quoteArg(cfg.Executable))
+	for _, arg := range cfg.Args {
+		execStart = append(execStart, quoteArg(arg))
+	}
+
+	var b strings.Builder
+	b.WriteString("# Generated by `pangolin service install`, changes are overwritten on reinstall\n")
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s\n", cfg.Description)
+	b.WriteString("Wants=network-online.target\n")
+	b.WriteString("After=network-online.target\n")
+	b.WriteString("\n")
+	b.WriteString("[Service]\n")
+	b.WriteString("Type=simple\n")
+	fmt.Fprintf(&b, "Environment=%s=1\n", ServiceEnvVar)
+	if cfg.EnvironmentFile != "" {
+		fmt.Fprintf(&b, "EnvironmentFile=%s\n", cfg.EnvironmentFile)
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(execStart, " "))
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=5\n")
+	b.WriteString("\n")
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=multi-user.target\n")
+
+	return b.String()
+}
+
+// EnvironmentFile returns the contents of an environment file that sets
+// the given variables, sorted by name
+func EnvironmentFile(vars map[string]string) string {
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		fmt.Fprintf(&b, "%s=%s\n", name, quoteEnvValue(vars[name]))
+	}
+	return b.String()
+}
+
+// quoteArg quotes a command line argument for ExecStart, escaping the
+// characters systemd would otherwise expand or split on
+func quoteArg(arg string) string {
+	arg = strings.ReplaceAll(arg, "%", "%%")
+	arg = strings.ReplaceAll(arg, "$", "$$")
+
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\"'\\;") {
+		return arg
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(arg) + `"`
+}
+
+// quoteEnvValue quotes a value for an environment file
+func quoteEnvValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(value) + `"`
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestQuoteArg(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  string
+		want string
+	}{
+		{"plain", "--attach", "--attach"},
+		{"empty", "", `""`},
+		{"space", "my org", `"my org"`},
+		{"percent", "100%", "100%%"},
+		{"dollar", "$HOME", "$$HOME"},
+		{"percent and space", "a %i b", `"a %%i b"`},
+		{"double quote", `say "hi"`, `"say \"hi\""`},
+		{"single quote", "it's", `"it's"`},
+		{"backslash", `C:\path`, `"C:\\path"`},
+		{"semicolon", ";", `";"`},
+		{"newline", "a\nb", `"a\nb"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteArg(tt.arg); got != tt.want {
+				t.Errorf("quoteArg(%q) = %s, want %s", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteEnvValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"plain", "secret", `"secret"`},
+		{"empty", "", `""`},
+		{"space", "a b", `"a b"`},
+		{"percent", "100%", `"100%"`},
+		{"dollar", "pa$$word", `"pa\$\$word"`},
+		{"double quote", `a"b`, `"a\"b"`},
+		{"single quote", "a'b", `"a'b"`},
+		{"backslash", `a\b`, `"a\\b"`},
+		{"backtick", "a`b`", "\"a\\`b\\`\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteEnvValue(tt.value); got != tt.want {
+				t.Errorf("quoteEnvValue(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		orgID    string
+		endpoint string
+		options  map[string]string
+		want     []string
+	}{
+		{
+			name:     "no org or options",
+			endpoint: "https://pangolin.example.com",
+			want:     []string{"up", "client", "--attach", "--no-project", "--endpoint", "https://pangolin.example.com"},
+		},
+		{
+			name:     "org and sorted options",
+			orgID:    "my-org",
+			endpoint: "https://pangolin.example.com",
+			options: map[string]string{
+				"tunnel_dns":     "true",
+				"interface_name": "pangolin 1",
+				"mtu":            "1280",
+			},
+			want: []string{
+				"up", "client", "--attach", "--no-project", "--endpoint", "https://pangolin.example.com",
+				"--org", "my-org",
+				"--interface-name=pangolin 1",
+				"--mtu=1280",
+				"--tunnel-dns=true",
+			},
+		},
+		{
+			name:     "empty option value",
+			endpoint: "https://pangolin.example.com",
+			options:  map[string]string{"netstack_dns": ""},
+			want:     []string{"up", "client", "--attach", "--no-project", "--endpoint", "https://pangolin.example.com", "--netstack-dns="},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClientArgs(tt.orgID, tt.endpoint, tt.options); !slices.Equal(got, tt.want) {
+				t.Errorf("ClientArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      UnitConfig
+		contains []string
+		excludes []string
+	}{
+		{
+			name: "quoted arguments",
+			cfg: UnitConfig{
+				Description:     "Pangolin client",
+				Executable:      "/usr/local/bin/pangolin",
+				Args:            []string{"up", "client", "--interface-name=my tun", "--org", "100%", "$org", `a"b`, ""},
+				EnvironmentFile: EnvironmentFilePath,
+			},
+			contains: []string{
+				"Description=Pangolin client\n",
+				"Environment=" + ServiceEnvVar + "=1\n",
+				"EnvironmentFile=" + EnvironmentFilePath + "\n",
+				`ExecStart=/usr/local/bin/pangolin up client "--interface-name=my tun" --org 100%% $$org "a\"b" ""` + "\n",
+				"WantedBy=multi-user.target\n",
+			},
+		},
+		{
+			name: "executable with space",
+			cfg: UnitConfig{
+				Description: "Pangolin client",
+				Executable:  "/opt/my apps/pangolin",
+				Args:        []string{"up"},
+			},
+			contains: []string{`ExecStart="/opt/my apps/pangolin" up` + "\n"},
+			excludes: []string{"EnvironmentFile="},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnitFile(tt.cfg)
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("UnitFile() does not contain %q:\n%s", want, got)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("UnitFile() contains %q:\n%s", unwanted, got)
+				}
+			}
+		})
+	}
+}
+
+func TestEnvironmentFile(t *testing.T) {
+	tests := []struct {
+		name string
+		vars map[string]string
+		want string
+	}{
+		{
+			name: "empty",
+			vars: map[string]string{},
+			want: "",
+		},
+		{
+			name: "sorted and quoted",
+			vars: map[string]string{
+				"PANGOLIN_OLM_SECRET": `s3cr$t "x" 50%`,
+				"PANGOLIN_OLM_ID":     "olm id",
+				"PANGOLIN_USER_TOKEN": "",
+			},
+			want: "PANGOLIN_OLM_ID=\"olm id\"\n" +
+				"PANGOLIN_OLM_SECRET=\"s3cr\\$t \\\"x\\\" 50%\"\n" +
+				"PANGOLIN_USER_TOKEN=\"\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EnvironmentFile(tt.vars); got != tt.want {
+				t.Errorf("EnvironmentFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
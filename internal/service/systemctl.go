@@ -0,0 +1,164 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Status describes the installed systemd unit
+type Status struct {
+	Installed bool
+	Enabled   bool
+	// ActiveState is the state reported by systemd, such as active,
+	// activating or inactive
+	ActiveState string
+}
+
+// Supported reports whether systemd services can be managed on this
+// system
+func Supported() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	_, err := exec.LookPath("systemctl")
+	return err == nil
+}
+
+// GetStatus returns the status of the unit
+func GetStatus() Status {
+	status := Status{ActiveState: "inactive"}
+
+	if _, err := os.Stat(UnitPath); err == nil {
+		status.Installed = true
+	}
+
+	if !Supported() {
+		return status
+	}
+
+	if err := exec.Command("systemctl", "is-enabled", "--quiet", UnitName).Run(); err == nil {
+		status.Enabled = true
+	}
+
+	status.ActiveState = activeState()
+
+	return status
+}
+
+// activeState returns the state of the unit reported by systemd
+func activeState() string {
+	if !Supported() {
+		return "inactive"
+	}
+
+	// is-active exits with a non-zero status for every state other than
+	// active, so only its output is used
+	out, _ := exec.Command("systemctl", "is-active", UnitName).Output()
+	if state := strings.TrimSpace(string(out)); state != "" {
+		return state
+	}
+	return "inactive"
+}
+
+// IsActive reports whether systemd is running the client, or about to
+// run it again after a failure. The unit then owns the client, and the
+// client must not be started or stopped outside of systemd.
+func IsActive() bool {
+	switch activeState() {
+	case "active", "activating", "reloading", "deactivating":
+		return true
+	default:
+		return false
+	}
+}
+
+// Install writes the unit and environment files, then enables and
+// starts the unit
+func Install(unit string, environment string) error {
+	if !Supported() {
+		return errors.New("systemd services are only supported on Linux systems running systemd")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(EnvironmentFilePath), 0o755); err != nil {
+		return err
+	}
+
+	if err := writeFile(EnvironmentFilePath, environment, 0o600); err != nil {
+		return err
+	}
+
+	if err := writeFile(UnitPath, unit, 0o644); err != nil {
+		return err
+	}
+
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+
+	// Restart rather than start, so that reinstalling applies the
+	// new configuration to a running unit
+	if err := systemctl("enable", UnitName); err != nil {
+		return err
+	}
+
+	return systemctl("restart", UnitName)
+}
+
+// Uninstall stops and disables the unit, then removes the unit and
+// environment files
+func Uninstall() error {
+	if !Supported() {
+		return errors.New("systemd services are only supported on Linux systems running systemd")
+	}
+
+	if err := systemctl("disable", "--now", UnitName); err != nil {
+		return err
+	}
+
+	for _, path := range []string{UnitPath, EnvironmentFilePath} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return systemctl("daemon-reload")
+}
+
+// writeFile replaces the contents of the file and sets its permissions,
+// including when the file already existed with other permissions
+func writeFile(path string, contents string, perm os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if err := file.Chmod(perm); err != nil {
+		file.Close()
+		return err
+	}
+
+	if _, err := file.WriteString(contents); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// systemctl runs systemctl with the given arguments
+func systemctl(args ...string) error {
+	out, err := exec.Command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		message := strings.TrimSpace(string(out))
+		if message == "" {
+			message = err.Error()
+		}
+		return fmt.Errorf("systemctl %s: %s", strings.Join(args, " "), message)
+	}
+	return nil
+}
@@ -0,0 +1,617 @@
+package tui
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fosrl/cli/internal/logger"
+	"github.com/fosrl/cli/internal/olm"
+)
+
+const (
+	// dashboardRefreshInterval is how often the RTT of the peers is
+	// sampled, which is not reported by events, and the site log reread
+	dashboardRefreshInterval = time.Second
+	// rttHistoryLength is the number of RTT samples kept for the sparkline
+	rttHistoryLength = 30
+	// staleAfter is how long a peer may go without a handshake before it
+	// is shown as stale
+	staleAfter = 3 * time.Minute
+	// siteLogLines is the number of log lines shown for a site
+	siteLogLines = 20
+	// siteLogScanSize is how much of the end of the log file is searched
+	// for the lines of a site
+	siteLogScanSize = 1 << 20
+)
+
+// sparkline are the bars of the RTT sparkline, from lowest to highest
+var sparkline = []rune("▁▂▃▄▅▆▇█")
+
+// sortOrder is the order the peers of the dashboard are listed in
+type sortOrder int
+
+const (
+	sortByName sortOrder = iota
+	sortBySiteID
+	sortByRTT
+	sortByLastSeen
+)
+
+var sortOrderNames = []string{"name", "site id", "rtt", "last seen"}
+
+// DashboardConfig configures the live status dashboard
+type DashboardConfig struct {
+	// SocketPath is the control socket of the client (default: the
+	// default client socket)
+	SocketPath string
+	// LogFile is the log file of the client, searched for the log lines
+	// of a site
+	LogFile string
+	Header  string
+}
+
+// dashboardModel is the bubbletea model for the live status dashboard
+type dashboardModel struct {
+	config    DashboardConfig
+	olmClient *olm.Client
+	ctx       context.Context
+	events    <-chan olm.Event
+	status    *olm.StatusResponse
+	err       error
+
+	// fetching and loadingLog are set while the status or the site log
+	// are read in the background
+	fetching   bool
+	loadingLog bool
+
+	// rttHistory holds the last RTT samples of each site, oldest first
+	rttHistory map[int][]time.Duration
+	// relayedSince holds when sites that were connected directly
+	// switched to the relay
+	relayedSince map[int]time.Time
+	// direct holds the sites seen connected without the relay
+	direct map[int]bool
+
+	sort      sortOrder
+	filter    string
+	filtering bool
+	// selected is the site ID of the selected peer
+	selected int
+
+	// logSite is the peer whose log lines are shown, if any
+	logSite  *olm.OLMPeerStatus
+	logLines []string
+
+	width int
+}
+
+// NewDashboard creates and runs the live status dashboard until the user
+// quits it. The status is refreshed on every event of the client, and
+// sampled periodically for the RTT history.
+func NewDashboard(config DashboardConfig) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	model := &dashboardModel{
+		config:       config,
+		olmClient:    olm.NewClient(config.SocketPath),
+		ctx:          ctx,
+		rttHistory:   map[int][]time.Duration{},
+		relayedSince: map[int]time.Time{},
+		direct:       map[int]bool{},
+		selected:     -1,
+	}
+
+	program := tea.NewProgram(model, tea.WithAltScreen())
+	_, err := program.Run()
+	return err
+}
+
+// Messages of the dashboard
+type (
+	// dashboardRefreshMsg triggers a sample of the status
+	dashboardRefreshMsg struct{}
+	// dashboardStatusMsg carries the status read in the background.
+	// Sampled statuses are recorded in the RTT history.
+	dashboardStatusMsg struct {
+		status  *olm.StatusResponse
+		err     error
+		sampled bool
+	}
+	// siteLogMsg carries the log lines of a site read in the background
+	siteLogMsg struct {
+		siteID int
+		lines  []string
+	}
+)
+
+// tickDashboardRefresh sends a refresh tick
+func tickDashboardRefresh() tea.Cmd {
+	return tea.Tick(dashboardRefreshInterval, func(t time.Time) tea.Msg {
+		return dashboardRefreshMsg{}
+	})
+}
+
+// Init initializes the model
+func (m *dashboardModel) Init() tea.Cmd {
+	return tea.Batch(
+		m.fetchStatus(true),
+		subscribeEvents(m.ctx, m.olmClient, 0),
+		tickDashboardRefresh(),
+	)
+}
+
+// Update handles messages
+func (m *dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+
+	case dashboardRefreshMsg:
+		return m, tea.Batch(m.fetchStatus(true), m.loadSiteLog(), tickDashboardRefresh())
+
+	case subscribedMsg:
+		m.events = msg.events
+		return m, tea.Batch(m.fetchStatus(false), waitForEvent(m.events))
+
+	case eventMsg:
+		if !msg.ok {
+			// Client went away - wait for it to come back
+			m.events = nil
+			return m, subscribeEvents(m.ctx, m.olmClient, subscribeRetryInterval)
+		}
+		return m, tea.Batch(m.fetchStatus(false), waitForEvent(m.events))
+
+	case dashboardStatusMsg:
+		m.fetching = false
+		m.refresh(msg)
+		return m, nil
+
+	case siteLogMsg:
+		m.loadingLog = false
+		if m.logSite == nil {
+			return m, nil
+		}
+		if m.logSite.SiteID != msg.siteID {
+			// Another site was selected meanwhile
+			return m, m.loadSiteLog()
+		}
+		m.logLines = msg.lines
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+	}
+	return m, nil
+}
+
+// handleKey handles a key press
+func (m *dashboardModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+
+	if m.filtering {
+		switch msg.Type {
+		case tea.KeyEnter:
+			m.filtering = false
+		case tea.KeyEsc:
+			m.filtering = false
+			m.filter = ""
+		case tea.KeyBackspace:
+			if len(m.filter) > 0 {
+				_, size := utf8.DecodeLastRuneInString(m.filter)
+				m.filter = m.filter[:len(m.filter)-size]
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			m.filter += string(msg.Runes)
+		}
+		return m, nil
+	}
+
+	if m.logSite != nil {
+		switch msg.String() {
+		case "q":
+			return m, tea.Quit
+		case "esc", "l", "enter":
+			m.logSite = nil
+			m.logLines = nil
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "q", "esc":
+		return m, tea.Quit
+	case "up", "k":
+		m.moveSelection(-1)
+	case "down", "j":
+		m.moveSelection(1)
+	case "s":
+		m.sort = (m.sort + 1) % sortOrder(len(sortOrderNames))
+	case "/":
+		m.filtering = true
+	case "l", "enter":
+		if peer := m.selectedPeer(); peer != nil {
+			m.logSite = peer
+			m.logLines = nil
+			return m, m.loadSiteLog()
+		}
+	}
+	return m, nil
+}
+
+// fetchStatus reads the status in the background, unless a read is
+// already in progress, so that a slow client does not block the UI
+func (m *dashboardModel) fetchStatus(sampled bool) tea.Cmd {
+	if m.fetching {
+		return nil
+	}
+	m.fetching = true
+
+	client := m.olmClient
+	return func() tea.Msg {
+		status, err := client.GetStatus()
+		return dashboardStatusMsg{status: status, err: err, sampled: sampled}
+	}
+}
+
+// loadSiteLog reads the log lines of the shown site in the background,
+// unless a read is already in progress
+func (m *dashboardModel) loadSiteLog() tea.Cmd {
+	if m.logSite == nil || m.loadingLog {
+		return nil
+	}
+	m.loadingLog = true
+
+	logFile := m.config.LogFile
+	peer := *m.logSite
+	return func() tea.Msg {
+		return siteLogMsg{siteID: peer.SiteID, lines: siteLogLinesOf(logFile, &peer, siteLogLines)}
+	}
+}
+
+// refresh applies the status read in the background and records the
+// relay history, and the RTT history for sampled statuses
+func (m *dashboardModel) refresh(msg dashboardStatusMsg) {
+	if msg.err != nil {
+		m.err = msg.err
+		return
+	}
+	m.err = nil
+	m.status = msg.status
+
+	now := time.Now()
+	for id, peer := range msg.status.PeerStatuses {
+		if peer.Connected && msg.sampled {
+			history := append(m.rttHistory[id], peer.RTT)
+			if len(history) > rttHistoryLength {
+				history = history[len(history)-rttHistoryLength:]
+			}
+			m.rttHistory[id] = history
+		}
+
+		switch {
+		case !peer.IsRelay:
+			delete(m.relayedSince, id)
+			if peer.Connected {
+				m.direct[id] = true
+			}
+		case m.direct[id]:
+			// Only peers seen connected directly flipped to the relay
+			if _, ok := m.relayedSince[id]; !ok {
+				m.relayedSince[id] = now
+			}
+		}
+	}
+}
+
+// peers returns the peers matching the filter, in the selected order
+func (m *dashboardModel) peers() []*olm.OLMPeerStatus {
+	if m.status == nil {
+		return nil
+	}
+
+	filter := strings.ToLower(m.filter)
+	var peers []*olm.OLMPeerStatus
+	for _, peer := range m.status.PeerStatuses {
+		if filter == "" ||
+			strings.Contains(strings.ToLower(peer.SiteName), filter) ||
+			strings.Contains(strings.ToLower(peer.Endpoint), filter) ||
+			strconv.Itoa(peer.SiteID) == filter {
+			peers = append(peers, peer)
+		}
+	}
+
+	slices.SortFunc(peers, func(a, b *olm.OLMPeerStatus) int {
+		var c int
+		switch m.sort {
+		case sortBySiteID:
+			c = a.SiteID - b.SiteID
+		case sortByRTT:
+			c = cmp.Compare(a.RTT, b.RTT)
+		case sortByLastSeen:
+			// Most recently seen first
+			c = b.LastSeen.Compare(a.LastSeen)
+		}
+		if c == 0 {
+			c = strings.Compare(strings.ToLower(a.SiteName), strings.ToLower(b.SiteName))
+		}
+		if c == 0 {
+			c = a.SiteID - b.SiteID
+		}
+		return c
+	})
+
+	return peers
+}
+
+// selectedPeer returns the selected peer, or the first one if the
+// selected peer is not listed
+func (m *dashboardModel) selectedPeer() *olm.OLMPeerStatus {
+	peers := m.peers()
+	for _, peer := range peers {
+		if peer.SiteID == m.selected {
+			return peer
+		}
+	}
+	if len(peers) > 0 {
+		m.selected = peers[0].SiteID
+		return peers[0]
+	}
+	return nil
+}
+
+// moveSelection moves the selection up or down the listed peers
+func (m *dashboardModel) moveSelection(delta int) {
+	peers := m.peers()
+	if len(peers) == 0 {
+		return
+	}
+
+	index := slices.IndexFunc(peers, func(peer *olm.OLMPeerStatus) bool {
+		return peer.SiteID == m.selected
+	})
+	index = max(0, min(len(peers)-1, index+delta))
+	m.selected = peers[index].SiteID
+}
+
+// View renders the model
+func (m *dashboardModel) View() string {
+	if m.logSite != nil {
+		return m.logView()
+	}
+
+	var sb strings.Builder
+
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(logger.ColorLightGray))
+	headerStyle := lipgloss.NewStyle().Bold(true)
+	selectedStyle := lipgloss.NewStyle().Reverse(true)
+	relayStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(logger.ColorWarning))
+	staleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(logger.ColorError))
+
+	if m.config.Header != "" {
+		sb.WriteString(m.config.Header)
+		sb.WriteString("\n\n")
+	}
+
+	switch {
+	case m.err != nil:
+		sb.WriteString(staleStyle.Render("Client unreachable: " + m.err.Error()))
+		sb.WriteString("\n")
+	case m.status != nil:
+		sb.WriteString(fmt.Sprintf("Org: %s   Status: %s   Registered: %t",
+			m.status.OrgID, formatConnected(m.status.Connected), m.status.Registered))
+		if m.status.Terminated {
+			sb.WriteString(staleStyle.Render("   Terminated"))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	selected := m.selectedPeer()
+	peers := m.peers()
+
+	sb.WriteString(headerStyle.Render(fmt.Sprintf("  %-20s %-22s %-12s %8s  %-*s  %-10s %s",
+		"SITE", "ENDPOINT", "STATUS", "RTT", rttHistoryLength, "RTT HISTORY", "LAST SEEN", "RELAY")))
+	sb.WriteString("\n")
+
+	if len(peers) == 0 {
+		sb.WriteString(dimStyle.Render("  No peers"))
+		sb.WriteString("\n")
+	}
+
+	now := time.Now()
+	for _, peer := range peers {
+		stale := peer.Connected && !peer.LastSeen.IsZero() && now.Sub(peer.LastSeen) > staleAfter
+		_, flipped := m.relayedSince[peer.SiteID]
+
+		status := formatConnected(peer.Connected)
+		if stale {
+			status = "Stale"
+		}
+		relay := "-"
+		if peer.IsRelay {
+			relay = "yes"
+		}
+
+		line := fmt.Sprintf("  %-20s %-22s %-12s %8s  %-*s  %-10s %s",
+			truncate(peer.SiteName, 20),
+			truncate(peer.Endpoint, 22),
+			status,
+			formatRTT(peer.RTT),
+			rttHistoryLength, renderSparkline(m.rttHistory[peer.SiteID]),
+			formatAge(peer.LastSeen, now),
+			relay)
+
+		switch {
+		case selected != nil && peer.SiteID == selected.SiteID:
+			line = selectedStyle.Render(line)
+		case stale:
+			line = staleStyle.Render(line)
+		case flipped:
+			line = relayStyle.Render(line)
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	if m.filtering {
+		sb.WriteString("Filter: " + m.filter + "█\n")
+		sb.WriteString(dimStyle.Render("enter: apply • esc: clear"))
+	} else {
+		if m.filter != "" {
+			sb.WriteString("Filter: " + m.filter + "\n")
+		}
+		sb.WriteString(dimStyle.Render(fmt.Sprintf("↑/↓: select • s: sort (%s) • /: filter • l: site logs • q: quit",
+			sortOrderNames[m.sort])))
+	}
+
+	return sb.String()
+}
+
+// logView renders the log lines of the selected site
+func (m *dashboardModel) logView() string {
+	var sb strings.Builder
+
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(logger.ColorLightGray))
+
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Render("Logs of site " + m.logSite.SiteName))
+	sb.WriteString("\n\n")
+
+	if len(m.logLines) == 0 {
+		sb.WriteString(dimStyle.Render("No recent log lines mention this site"))
+		sb.WriteString("\n")
+	}
+
+	for _, line := range m.logLines {
+		if m.width > 3 && len(line) > m.width {
+			line = line[:m.width-3] + "..."
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(dimStyle.Render("esc: back • q: quit"))
+
+	return sb.String()
+}
+
+// siteLogLinesOf returns the last n lines of the log file that mention
+// the site by name or endpoint. Only the end of the file is searched.
+func siteLogLinesOf(logPath string, peer *olm.OLMPeerStatus, n int) []string {
+	file, err := os.Open(logPath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil
+	}
+
+	offset := max(0, info.Size()-siteLogScanSize)
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil
+	}
+
+	scanner := bufio.NewScanner(file)
+	if offset > 0 {
+		// Skip the partial first line
+		scanner.Scan()
+	}
+
+	var lines []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if (peer.SiteName != "" && strings.Contains(line, peer.SiteName)) ||
+			(peer.Endpoint != "" && strings.Contains(line, peer.Endpoint)) {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// renderSparkline renders the RTT samples as a sparkline scaled between
+// the lowest and highest sample
+func renderSparkline(samples []time.Duration) string {
+	if len(samples) == 0 {
+		return ""
+	}
+
+	lowest, highest := slices.Min(samples), slices.Max(samples)
+
+	var sb strings.Builder
+	for _, sample := range samples {
+		level := 0
+		if highest > lowest {
+			level = int((sample - lowest) * time.Duration(len(sparkline)-1) / (highest - lowest))
+		}
+		sb.WriteRune(sparkline[level])
+	}
+	return sb.String()
+}
+
+// formatRTT formats the round trip time of a peer
+func formatRTT(rtt time.Duration) string {
+	if rtt <= 0 {
+		return "-"
+	}
+	if rtt < 10*time.Millisecond {
+		return fmt.Sprintf("%.1fms", float64(rtt)/float64(time.Millisecond))
+	}
+	return rtt.Round(time.Millisecond).String()
+}
+
+// formatAge formats how long ago the time was
+func formatAge(t time.Time, now time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	diff := now.Sub(t)
+	switch {
+	case diff < time.Minute:
+		return fmt.Sprintf("%.0fs ago", diff.Seconds())
+	case diff < time.Hour:
+		return fmt.Sprintf("%.0fm ago", diff.Minutes())
+	default:
+		return fmt.Sprintf("%.1fh ago", diff.Hours())
+	}
+}
+
+// formatConnected formats a connection status
+func formatConnected(connected bool) string {
+	if connected {
+		return "Connected"
+	}
+	return "Disconnected"
+}
+
+// truncate shortens the string to at most n characters
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
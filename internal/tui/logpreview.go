@@ -2,6 +2,8 @@ package tui
 
 import (
 	"bufio"
+	"context"
+	"fmt"
 	"io"
 	"os"
 	"strings"
@@ -22,7 +24,10 @@ type StatusFormatter func(isRunning bool, status *olm.StatusResponse) string
 
 // LogPreviewConfig configures the log preview TUI
 type LogPreviewConfig struct {
-	LogFile         string
+	LogFile string
+	// SocketPath is the control socket of the client (default: the
+	// default client socket)
+	SocketPath      string
 	Header          string
 	ExitCondition   ExitCondition
 	OnEarlyExit     func(client *olm.Client) // Called when user exits early (Ctrl+C)
@@ -33,7 +38,11 @@ type LogPreviewConfig struct {
 type logPreviewModel struct {
 	config        LogPreviewConfig
 	olmClient     *olm.Client
+	ctx           context.Context
+	events        <-chan olm.Event
+	authError     string
 	logLines      []string
+	isRunning     bool
 	status        *olm.StatusResponse
 	lastLogPos    int64
 	completedTime *time.Time
@@ -41,11 +50,18 @@ type logPreviewModel struct {
 	width         int
 }
 
-// NewLogPreview creates and runs a new log preview TUI
+// NewLogPreview creates and runs a new log preview TUI. The status is
+// refreshed on every event of the client, subscribing again whenever the
+// client comes up, and periodically in case it changes without an event.
+// An authentication error of the client ends the preview with an error.
 func NewLogPreview(config LogPreviewConfig) (completed bool, err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	model := &logPreviewModel{
 		config:    config,
-		olmClient: olm.NewClient(""),
+		olmClient: olm.NewClient(config.SocketPath),
+		ctx:       ctx,
 		logLines:  []string{},
 	}
 
@@ -56,6 +72,9 @@ func NewLogPreview(config LogPreviewConfig) (completed bool, err error) {
 	}
 
 	if previewModel, ok := finalModel.(*logPreviewModel); ok {
+		if previewModel.authError != "" {
+			return false, fmt.Errorf("authentication failed: %s", previewModel.authError)
+		}
 		return previewModel.completed, nil
 	}
 	return false, nil
@@ -69,7 +88,8 @@ func (m *logPreviewModel) Init() tea.Cmd {
 			// After delay, start the tickers
 			return initCompleteMsg{}
 		}),
-		tickStatusUpdate(),
+		subscribeEvents(m.ctx, m.olmClient, 0),
+		tickStatusRefresh(),
 	)
 }
 
@@ -101,38 +121,42 @@ func (m *logPreviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, tickLogUpdate()
 
-	case statusUpdateMsg:
-		// Update status
-		isRunning := m.olmClient.IsRunning()
+	case statusRefreshMsg:
+		return m, tea.Batch(m.fetchStatus(), tickStatusRefresh())
 
-		if isRunning {
-			status, err := m.olmClient.GetStatus()
-			if err == nil {
-				m.status = status
-			}
-		} else {
+	case subscribedMsg:
+		m.events = msg.events
+		return m, tea.Batch(m.fetchStatus(), waitForEvent(m.events))
+
+	case eventMsg:
+		if !msg.ok {
+			// Client went away - refresh status and wait for it to come back
+			m.events = nil
+			return m, tea.Batch(m.fetchStatus(), subscribeEvents(m.ctx, m.olmClient, subscribeRetryInterval))
+		}
+		if msg.event.Type == olm.EventAuthError {
+			m.authError = msg.event.Message
+			m.completed = false
+			return m, tea.Quit
+		}
+		return m, tea.Batch(m.fetchStatus(), waitForEvent(m.events))
+
+	case statusMsg:
+		m.isRunning = msg.isRunning
+		if !msg.isRunning {
 			// Socket doesn't exist - clear status
 			m.status = nil
+		} else if msg.status != nil {
+			m.status = msg.status
 		}
+		return m, m.checkExit()
 
-		// Check exit condition (pass current status, even if nil)
-		if m.config.ExitCondition != nil {
-			shouldExit, completed := m.config.ExitCondition(m.olmClient, m.status)
-			if shouldExit {
-				if m.completedTime == nil {
-					now := time.Now()
-					m.completedTime = &now
-					m.completed = completed
-				} else if time.Since(*m.completedTime) >= 1*time.Second {
-					// Wait 1 second, then exit
-					return m, tea.Quit
-				}
-			} else {
-				// Reset completed time if condition no longer met
-				m.completedTime = nil
-			}
+	case exitMsg:
+		// Exit once the condition held for the whole delay
+		if m.completedTime != nil && time.Since(*m.completedTime) >= exitDelay {
+			return m, tea.Quit
 		}
-		return m, tickStatusUpdate()
+		return m, nil
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -141,6 +165,83 @@ func (m *logPreviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// fetchStatus fetches the status of the client in the background
+func (m *logPreviewModel) fetchStatus() tea.Cmd {
+	client := m.olmClient
+	return func() tea.Msg {
+		if !client.IsRunning() {
+			return statusMsg{}
+		}
+
+		status, err := client.GetStatus()
+		if err != nil {
+			// Keep the last known status
+			return statusMsg{isRunning: true}
+		}
+		return statusMsg{isRunning: true, status: status}
+	}
+}
+
+// checkExit checks the exit condition against the current status. Once
+// the condition is met, the preview exits after a delay unless the
+// condition no longer holds by then.
+func (m *logPreviewModel) checkExit() tea.Cmd {
+	if m.config.ExitCondition == nil {
+		return nil
+	}
+
+	// Pass current status, even if nil
+	shouldExit, completed := m.config.ExitCondition(m.olmClient, m.status)
+	if !shouldExit {
+		// Reset completed time if condition no longer met
+		m.completedTime = nil
+		return nil
+	}
+
+	if m.completedTime == nil {
+		now := time.Now()
+		m.completedTime = &now
+		m.completed = completed
+		return tea.Tick(exitDelay, func(t time.Time) tea.Msg {
+			return exitMsg{}
+		})
+	}
+	return nil
+}
+
+// subscribeEvents subscribes to the events of the client after the
+// delay, retrying until the client is up
+func subscribeEvents(ctx context.Context, client *olm.Client, delay time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+
+		for {
+			events, err := client.Subscribe(ctx)
+			if err == nil {
+				return subscribedMsg{events: events}
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(subscribeRetryInterval):
+			}
+		}
+	}
+}
+
+// waitForEvent waits for the next event of the subscription
+func waitForEvent(events <-chan olm.Event) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-events
+		return eventMsg{event: event, ok: ok}
+	}
+}
+
 // View renders the model
 func (m *logPreviewModel) View() string {
 	var sb strings.Builder
@@ -168,16 +269,37 @@ func (m *logPreviewModel) View() string {
 
 	// Status line
 	sb.WriteString("Status: ")
-	sb.WriteString(m.config.StatusFormatter(m.olmClient.IsRunning(), m.status))
+	sb.WriteString(m.config.StatusFormatter(m.isRunning, m.status))
 
 	return sb.String()
 }
 
+const (
+	// subscribeRetryInterval is how often the subscription is retried
+	// while the client is not up
+	subscribeRetryInterval = 500 * time.Millisecond
+	// statusRefreshInterval is how often the status is refreshed
+	// between events
+	statusRefreshInterval = 500 * time.Millisecond
+	// exitDelay is how long the exit condition must hold before exiting
+	exitDelay = 1 * time.Second
+)
+
 // Messages for bubbletea
 type (
-	logUpdateMsg    struct{}
-	statusUpdateMsg struct{}
-	initCompleteMsg struct{}
+	logUpdateMsg     struct{}
+	initCompleteMsg  struct{}
+	exitMsg          struct{}
+	statusRefreshMsg struct{}
+	subscribedMsg    struct{ events <-chan olm.Event }
+	eventMsg         struct {
+		event olm.Event
+		ok    bool // false once the subscription is closed
+	}
+	statusMsg struct {
+		isRunning bool
+		status    *olm.StatusResponse
+	}
 )
 
 // tickLogUpdate sends a log update tick
@@ -187,10 +309,10 @@ func tickLogUpdate() tea.Cmd {
 	})
 }
 
-// tickStatusUpdate sends a status update tick
-func tickStatusUpdate() tea.Cmd {
-	return tea.Tick(500*time.Millisecond, func(t time.Time) tea.Msg {
-		return statusUpdateMsg{}
+// tickStatusRefresh sends a status refresh tick
+func tickStatusRefresh() tea.Cmd {
+	return tea.Tick(statusRefreshInterval, func(t time.Time) tea.Msg {
+		return statusRefreshMsg{}
 	})
 }
 
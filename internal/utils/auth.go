@@ -1,11 +1,14 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/fosrl/cli/internal/api"
 	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/daemon"
 )
 
 // GetDeviceName returns a human-readable device name
@@ -23,20 +26,30 @@ func GetDeviceName() string {
 //
 // If new ones are created, a "true" is returned to indicate we need to
 // save the new credentials to disk.
-func EnsureOlmCredentials(client *api.Client, account *config.Account) (bool, error) {
+func EnsureOlmCredentials(ctx context.Context, client *api.Client, account *config.Account) (bool, error) {
+	// API keys are not tied to a user, so OLM credentials can neither
+	// be verified nor created for them and must be provided up front.
+	if account.IsAPIKey() {
+		if account.OlmCredentials == nil {
+			return false, fmt.Errorf("API key accounts have no client credentials; pass --id and --secret or set %s and %s", daemon.OlmIDEnvVar, daemon.OlmSecretEnvVar)
+		}
+		return false, nil
+	}
+
 	userID := account.UserID
 
 	if account.OlmCredentials != nil {
-		serverCreds, err := client.GetUserOlm(userID, account.OlmCredentials.ID)
+		serverCreds, err := client.GetUserOlm(ctx, userID, account.OlmCredentials.ID)
 		if err == nil && serverCreds != nil {
 			return false, nil
 		}
 
 		// If getting OLM fails, the OLM might not exist.
 		// This requires regeneration; in case of any errors
-		// that are not API-related, these are likely not
-		// related to the credentials and should be bubbled up.
-		if _, ok := err.(*api.ErrorResponse); !ok {
+		// that are not caused by the credentials themselves
+		// (network failures, expired sessions, server errors),
+		// these should be bubbled up.
+		if !isInvalidCredentialsError(err) {
 			return false, fmt.Errorf("failed to get OLM: %w", err)
 		}
 
@@ -44,7 +57,7 @@ func EnsureOlmCredentials(client *api.Client, account *config.Account) (bool, er
 		account.OlmCredentials = nil
 	}
 
-	newOlm, err := client.CreateOlm(userID, GetDeviceName())
+	newOlm, err := client.CreateOlm(ctx, userID, GetDeviceName())
 	if err != nil {
 		return false, fmt.Errorf("failed to create OLM: %w", err)
 	}
@@ -58,15 +71,20 @@ func EnsureOlmCredentials(client *api.Client, account *config.Account) (bool, er
 }
 
 // EnsureOrgAccess ensures that the user has access to the organization
-func EnsureOrgAccess(client *api.Client, account *config.Account) error {
+func EnsureOrgAccess(ctx context.Context, client *api.Client, account *config.Account) error {
 	// Get org via API to ensure it exists
-	_, err := client.GetOrg(account.OrgID)
+	_, err := client.GetOrg(ctx, account.OrgID)
 	if err != nil {
 		return err
 	}
 
+	// Access policies apply to users, not API keys
+	if account.IsAPIKey() {
+		return nil
+	}
+
 	// Check org user access and policies
-	accessResponse, err := client.CheckOrgUserAccess(account.OrgID, account.UserID)
+	accessResponse, err := client.CheckOrgUserAccess(ctx, account.OrgID, account.UserID)
 	if err != nil {
 		return err
 	}
@@ -75,8 +93,21 @@ func EnsureOrgAccess(client *api.Client, account *config.Account) error {
 	if !accessResponse.Allowed {
 		// Get hostname base URL for constructing the web URL
 		url := fmt.Sprintf("%s/%s", FormatHostnameBaseURL(account.Host), account.OrgID)
-		return fmt.Errorf("Organization policy is preventing you from connecting. Please visit %s to complete required steps", url)
+		return fmt.Errorf("%w. Please visit %s to complete required steps", api.ErrPolicyViolation, url)
 	}
 
 	return nil
 }
+
+// isInvalidCredentialsError reports whether an error returned when
+// fetching OLM credentials means the credentials themselves are invalid
+func isInvalidCredentialsError(err error) bool {
+	var apiErr *api.ErrorResponse
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	return !errors.Is(err, api.ErrUnauthorized) &&
+		!errors.Is(err, api.ErrRateLimited) &&
+		!errors.Is(err, api.ErrServer)
+}
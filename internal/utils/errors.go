@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"errors"
+
+	"github.com/fosrl/cli/internal/api"
+	"github.com/fosrl/cli/internal/logger"
+)
+
+// Exit codes of commands, so that scripts can tell failures apart
+const (
+	ExitCodeError = 1
+	// ExitCodeTerminated means the client was terminated by the server
+	ExitCodeTerminated     = 3
+	ExitCodeAuthError      = 4
+	ExitCodeAlreadyRunning = 5
+	ExitCodePolicyDenied   = 6
+	ExitCodeTimeout        = 7
+)
+
+// ExitError is an error that makes the command exit with a specific code
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// NewExitError returns an error that makes the command exit with the
+// given code
+func NewExitError(code int, err error) *ExitError {
+	return &ExitError{Code: code, Err: err}
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code for an error returned by a command.
+// API errors that are reported with their own exit code are recognized
+// even when they are not wrapped in an ExitError.
+func ExitCode(err error) int {
+	var exitErr *ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exitErr):
+		return exitErr.Code
+	case errors.Is(err, api.ErrPolicyViolation):
+		return ExitCodePolicyDenied
+	case errors.Is(err, api.ErrUnauthorized):
+		return ExitCodeAuthError
+	default:
+		return ExitCodeError
+	}
+}
+
+// ErrorHint returns an actionable hint for a failed API call, or an
+// empty string if there is nothing more useful to say than the error.
+func ErrorHint(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, api.ErrUnauthorized):
+		return "Your session has expired or is invalid. Run `pangolin login` to authenticate again"
+	case errors.Is(err, api.ErrForbidden):
+		return "Your account does not have permission to do this. Run `pangolin select account` to use a different account"
+	case errors.Is(err, api.ErrRateLimited):
+		return "The server is rate limiting requests. Please wait a moment and try again"
+	case errors.Is(err, api.ErrServer):
+		return "The Pangolin server failed to process the request. Please try again later"
+	case errors.Is(err, api.ErrNetwork):
+		return "Could not reach the Pangolin server. Check your network connection and the configured host"
+	default:
+		return ""
+	}
+}
+
+// LogErrorHint logs the actionable hint for an error, if there is one
+func LogErrorHint(err error) {
+	if hint := ErrorHint(err); hint != "" {
+		logger.Info("%s", hint)
+	}
+}
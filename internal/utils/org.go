@@ -1,10 +1,14 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/charmbracelet/huh"
 	"github.com/fosrl/cli/internal/api"
+	"github.com/fosrl/cli/internal/config"
+	"github.com/fosrl/cli/internal/daemon"
+	"github.com/fosrl/cli/internal/hooks"
 	"github.com/fosrl/cli/internal/logger"
 	"github.com/fosrl/cli/internal/olm"
 )
@@ -12,19 +16,19 @@ import (
 // SelectOrgForm lists organizations for a user and prompts them to select one.
 // It returns the selected org ID and any error.
 // If the user has only one organization, it's automatically selected.
-func SelectOrgForm(client *api.Client, userID string) (string, error) {
-	orgsResp, err := client.ListUserOrgs(userID)
+func SelectOrgForm(ctx context.Context, client *api.Client, userID string) (string, error) {
+	orgs, err := api.CollectAll(client.UserOrgs(ctx, userID))
 	if err != nil {
 		return "", fmt.Errorf("failed to list organizations: %w", err)
 	}
 
-	if len(orgsResp.Orgs) == 0 {
+	if len(orgs) == 0 {
 		return "", fmt.Errorf("no organizations found for this user")
 	}
 
-	if len(orgsResp.Orgs) == 1 {
+	if len(orgs) == 1 {
 		// Auto-select if only one org
-		selectedOrg := orgsResp.Orgs[0]
+		selectedOrg := orgs[0]
 		return selectedOrg.OrgID, nil
 	}
 
@@ -35,7 +39,7 @@ func SelectOrgForm(client *api.Client, userID string) (string, error) {
 	}
 
 	var orgOptions []huh.Option[OrgOption]
-	for _, org := range orgsResp.Orgs {
+	for _, org := range orgs {
 		label := fmt.Sprintf("%s (%s)", org.Name, org.OrgID)
 		orgOptions = append(orgOptions, huh.NewOption(label, OrgOption{
 			OrgID: org.OrgID,
@@ -60,10 +64,11 @@ func SelectOrgForm(client *api.Client, userID string) (string, error) {
 	return selectedOrgOption.OrgID, nil
 }
 
-// SwitchActiveClientOrg checks if the OLM client is running and switches to the new org if so
+// SwitchActiveClientOrg checks if the OLM client is running and switches to the new org if so,
+// then runs the post_org_switch hooks.
 // It returns true if a switch was attempted (regardless of success)
-func SwitchActiveClientOrg(orgID string) bool {
-	client := olm.NewClient("")
+func SwitchActiveClientOrg(cfg *config.Config, orgID string) bool {
+	client := olm.NewClient(cfg.SocketPath)
 	if !client.IsRunning() {
 		// Client is not running, nothing to do
 		return false
@@ -81,14 +86,36 @@ func SwitchActiveClientOrg(orgID string) bool {
 		return false
 	}
 
-	// Client is running, try to switch org
-	_, err = client.SwitchOrg(orgID)
+	// Client is running, try to switch org. When the daemon manages the
+	// client, switch through the daemon so that it keeps track of the
+	// organization.
+	conn := hooks.Connection{
+		OrgID:         orgID,
+		PreviousOrgID: currentStatus.OrgID,
+	}
+
+	daemonClient := daemon.NewClient("")
+	if daemonStatus, statusErr := daemonClient.Status(""); statusErr == nil && daemonStatus.Running {
+		err = daemonClient.SwitchOrg("", orgID)
+
+		conn.Endpoint = daemonStatus.Endpoint
+		conn.InterfaceName = daemonStatus.InterfaceName
+		if conn.InterfaceName == "" {
+			conn.InterfaceName = olm.DefaultInterfaceName
+		}
+	} else {
+		_, err = client.SwitchOrg(orgID)
+	}
 	if err != nil {
 		logger.Warning("Failed to switch organization in active client: %v", err)
 		logger.Warning("The organization has been saved to config, but the active client may still be using the previous organization.")
 		return false
 	}
 
+	// The client registers with the new organization in the background,
+	// so its peers are not known yet
+	hooks.Run(cfg.Hooks, hooks.PostOrgSwitch, conn)
+
 	// Switch was sent successfully
 	return true
 }
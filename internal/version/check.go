@@ -20,6 +20,16 @@ const (
 	GitHubAPIBaseURL = "https://api.github.com"
 )
 
+// transport is the HTTP transport used for release checks. If nil,
+// http.DefaultTransport is used.
+var transport http.RoundTripper
+
+// SetTransport sets the HTTP transport used to fetch release information,
+// so that proxy and TLS settings also apply to update checks
+func SetTransport(rt http.RoundTripper) {
+	transport = rt
+}
+
 // GitHubRelease represents a GitHub release
 type GitHubRelease struct {
 	TagName string `json:"tag_name"`
@@ -32,7 +42,8 @@ func GetLatestRelease() (*GitHubRelease, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/releases/latest", GitHubAPIBaseURL, GitHubRepoOwner, GitHubRepoName)
 
 	client := &http.Client{
-		Timeout: 10 * time.Second,
+		Timeout:   10 * time.Second,
+		Transport: transport,
 	}
 
 	req, err := http.NewRequest("GET", url, nil)